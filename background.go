@@ -0,0 +1,149 @@
+package goapi
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+const (
+	defaultBackgroundWorkers = 16
+	backgroundQueueSize      = 1024
+)
+
+// BackgroundTasks It is the tasks executed by the worker pool of the API after the response is sent,
+// it is injected by the field '*goapi.BackgroundTasks' of the router input
+//
+// example:
+//
+//	Tasks *goapi.BackgroundTasks
+//	input.Tasks.Add(func(ctx context.Context) { sendEmail(ctx, user) })
+type BackgroundTasks struct {
+	log   Logger
+	tasks []func(ctx context.Context)
+	// set by the submit
+	ctx     context.Context
+	pending *sync.WaitGroup
+}
+
+// Add It is to add the tasks executed in order after the response is sent.
+// The panic of a task is recovered and written by the logger of the request, the next task is still executed.
+// The tasks are dropped with an error log when the queue of the worker pool is full or the API is shutting down.
+// The ctx is canceled when the API shuts down and the pending tasks are not finished before BackgroundTimeout
+func (b *BackgroundTasks) Add(tasks ...func(ctx context.Context)) {
+	b.tasks = append(b.tasks, tasks...)
+}
+
+// backgroundPool It is the bounded worker pool executing the BackgroundTasks, the workers are started by the first tasks.
+// The pending and the ctx are created again after the pool is closed by drain and opened by the startup,
+// so the tasks submitted after that are not waited by the last drain
+type backgroundPool struct {
+	mux     sync.Mutex
+	queue   chan *BackgroundTasks
+	pending *sync.WaitGroup
+	closed  bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// provideBackgroundTasks It is the provider of '*goapi.BackgroundTasks', the tasks are submitted after the response
+func (a *API) provideBackgroundTasks(ctx *Context) (*BackgroundTasks, func()) {
+	tasks := &BackgroundTasks{log: ctx.Logger()}
+	return tasks, func() {
+		if len(tasks.tasks) > 0 {
+			a.background.submit(a.BackgroundWorkers, tasks)
+		}
+	}
+}
+
+// submit It adds the tasks to the queue without waiting,
+// the tasks are dropped when the queue is full or the pool is closed
+func (p *backgroundPool) submit(workers int, tasks *BackgroundTasks) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.closed {
+		p.drop(tasks, "the API is shutting down")
+		return
+	}
+	if p.queue == nil {
+		if workers <= 0 {
+			workers = defaultBackgroundWorkers
+		}
+		p.queue = make(chan *BackgroundTasks, backgroundQueueSize)
+		for i := 0; i < workers; i++ {
+			go p.work()
+		}
+	}
+	if p.pending == nil {
+		p.pending = &sync.WaitGroup{}
+		p.ctx, p.cancel = context.WithCancel(context.Background())
+	}
+	tasks.ctx, tasks.pending = p.ctx, p.pending
+	p.pending.Add(1)
+	select {
+	case p.queue <- tasks:
+	default:
+		p.pending.Done()
+		p.drop(tasks, "the queue is full")
+	}
+}
+
+func (p *backgroundPool) drop(tasks *BackgroundTasks, reason string) {
+	if tasks.log != nil {
+		tasks.log.Error("%v background tasks are dropped: %v", len(tasks.tasks), reason)
+	}
+}
+
+func (p *backgroundPool) work() {
+	for tasks := range p.queue {
+		for _, task := range tasks.tasks {
+			p.run(tasks.ctx, tasks.log, task)
+		}
+		tasks.pending.Done()
+	}
+}
+
+func (p *backgroundPool) run(ctx context.Context, log Logger, task func(ctx context.Context)) {
+	defer func() {
+		if err := recover(); err != nil && log != nil {
+			log.Fatal("background task panic: %v [recovered]\n%v", err, string(debug.Stack()))
+		}
+	}()
+	task(ctx)
+}
+
+// open It accepts the tasks again after the pool is closed by drain, it is called by the startup
+func (p *backgroundPool) open() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.closed {
+		p.closed = false
+		p.pending, p.ctx, p.cancel = nil, nil, nil
+	}
+}
+
+// drain It closes the pool and waits for the pending tasks until the ctx is done,
+// then the ctx of the running tasks is canceled
+func (p *backgroundPool) drain(ctx context.Context) error {
+	p.mux.Lock()
+	p.closed = true
+	pending, cancel := p.pending, p.cancel
+	p.mux.Unlock()
+	if pending == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		cancel()
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return fmt.Errorf("the background tasks are not finished: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,187 @@
+package goapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type backgroundTestLogger struct {
+	nopLogger
+	mux    sync.Mutex
+	fatals []string
+	errs   []string
+}
+
+func (b *backgroundTestLogger) Error(format string, a ...any) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.errs = append(b.errs, fmt.Sprintf(format, a...))
+}
+
+func (b *backgroundTestLogger) Fatal(format string, a ...any) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.fatals = append(b.fatals, fmt.Sprintf(format, a...))
+}
+
+func (b *backgroundTestLogger) WithFields(keysAndValues ...any) Logger {
+	return b
+}
+
+func TestBackgroundTasks(t *testing.T) {
+	api := New(false)
+	log := &backgroundTestLogger{}
+	api.SetLogger(log)
+	api.BackgroundWorkers = 2
+	var mux sync.Mutex
+	var calls []string
+	responded := make(chan struct{})
+	Post(api, "/users", func(ctx *Context, in struct {
+		Tasks *BackgroundTasks
+	}) (string, error) {
+		in.Tasks.Add(func(ctx context.Context) {
+			<-responded
+			time.Sleep(50 * time.Millisecond)
+			mux.Lock()
+			calls = append(calls, "email")
+			mux.Unlock()
+		}, func(ctx context.Context) {
+			panic("broken task")
+		}, func(ctx context.Context) {
+			mux.Lock()
+			calls = append(calls, "audit")
+			mux.Unlock()
+		})
+		return "created", nil
+	})
+	addr := freeAddr(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.Run(addr)
+	}()
+	waitServing(t, addr)
+	resp, err := http.Post("http://"+addr+"/users", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	close(responded)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %v", resp.StatusCode)
+	}
+	if err = api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-runErr; err != nil {
+		t.Fatal(err)
+	}
+	// the pending tasks are drained by the shutdown
+	mux.Lock()
+	got := strings.Join(calls, ",")
+	mux.Unlock()
+	if got != "email,audit" {
+		t.Errorf("got tasks %q want %q", got, "email,audit")
+	}
+	log.mux.Lock()
+	defer log.mux.Unlock()
+	if len(log.fatals) != 1 || !strings.Contains(log.fatals[0], "background task panic: broken task") {
+		t.Errorf("the panic should be written by the logger, got %v", log.fatals)
+	}
+}
+
+func TestBackgroundPoolOverflow(t *testing.T) {
+	var pool backgroundPool
+	log := &backgroundTestLogger{}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var mux sync.Mutex
+	var count int
+	pool.submit(1, &BackgroundTasks{log: log, tasks: []func(ctx context.Context){func(ctx context.Context) {
+		close(started)
+		<-release
+	}}})
+	<-started
+	// the only worker is busy, the queue is filled and the next tasks are dropped without waiting
+	for i := 0; i < backgroundQueueSize+1; i++ {
+		pool.submit(1, &BackgroundTasks{log: log, tasks: []func(ctx context.Context){func(ctx context.Context) {
+			mux.Lock()
+			count++
+			mux.Unlock()
+		}}})
+	}
+	log.mux.Lock()
+	if len(log.errs) != 1 || !strings.Contains(log.errs[0], "the queue is full") {
+		t.Errorf("the dropped tasks should be written by the logger, got %v", log.errs)
+	}
+	log.mux.Unlock()
+	close(release)
+	if err := pool.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if count != backgroundQueueSize {
+		t.Errorf("got %v tasks want %v", count, backgroundQueueSize)
+	}
+}
+
+func TestBackgroundPoolClosed(t *testing.T) {
+	var pool backgroundPool
+	log := &backgroundTestLogger{}
+	done := make(chan struct{}, 2)
+	task := func(ctx context.Context) {
+		done <- struct{}{}
+	}
+	if err := pool.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// the tasks submitted after the drain are rejected, they are not waited by the drain
+	pool.submit(1, &BackgroundTasks{log: log, tasks: []func(ctx context.Context){task}})
+	log.mux.Lock()
+	if len(log.errs) != 1 || !strings.Contains(log.errs[0], "the API is shutting down") {
+		t.Errorf("the rejected tasks should be written by the logger, got %v", log.errs)
+	}
+	log.mux.Unlock()
+	pool.open()
+	pool.submit(1, &BackgroundTasks{log: log, tasks: []func(ctx context.Context){task}})
+	if err := pool.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 {
+		t.Errorf("got %v tasks want 1", len(done))
+	}
+}
+
+func TestBackgroundTimeout(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.BackgroundTimeout = 50 * time.Millisecond
+	api.LifespanTimeout = time.Second
+	canceled := make(chan struct{})
+	api.OnShutdown(func(ctx context.Context, log Logger) error {
+		deadline, _ := ctx.Deadline()
+		// the hooks are not affected by the background tasks using up their deadline
+		if ctx.Err() != nil || time.Until(deadline) < 500*time.Millisecond {
+			t.Errorf("the hooks should have their own deadline, got %v", time.Until(deadline))
+		}
+		return nil
+	})
+	if err := api.startup(); err != nil {
+		t.Fatal(err)
+	}
+	api.background.submit(1, &BackgroundTasks{tasks: []func(ctx context.Context){func(ctx context.Context) {
+		<-ctx.Done()
+		close(canceled)
+	}}})
+	if err := api.shutdown(); err == nil || !strings.Contains(err.Error(), "the background tasks are not finished") {
+		t.Errorf("got %v want the error of the background tasks", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("the ctx of the tasks should be canceled after BackgroundTimeout")
+	}
+}
@@ -0,0 +1,106 @@
+package goapi
+
+import (
+	"fmt"
+	"net"
+)
+
+type bindInfo struct {
+	addr     string
+	children []*RouterChild
+}
+
+// Bind It is to serve the routers and the docs of the children on the other address,
+// the children are removed from the address of Run.
+// The bound addresses are served by Run, RunTLS, RunListener and so on, and shut down together by Shutdown.
+// Each child keeps its own middlewares, the children sharing a docs path must be served on the same address
+//
+// example:
+//
+//	admin := api.Child("/admin", "/admin/docs")
+//	admin.DebugPprof()
+//	api.Bind("127.0.0.1:9090", admin)
+//	log.Fatal(api.Run(":8080"))
+func (a *API) Bind(addr string, children ...*RouterChild) {
+	a.binds = append(a.binds, bindInfo{
+		addr:     addr,
+		children: children,
+	})
+}
+
+// bindAddrMap returns the bound address by the children
+func (a *API) bindAddrMap() (addrMap map[*RouterChild]string, err error) {
+	addrMap = map[*RouterChild]string{}
+	var errs []error
+	for _, bind := range a.binds {
+		if bind.addr == "" {
+			errs = append(errs, fmt.Errorf("the address of Bind cannot be empty"))
+			continue
+		}
+		for _, child := range bind.children {
+			if child == nil || child == a.RouterChild {
+				errs = append(errs, fmt.Errorf("only the children created by API.Child can be bound"))
+				continue
+			}
+			if addr, ok := addrMap[child]; ok && addr != bind.addr {
+				errs = append(errs, fmt.Errorf("the child with the prefix '%v' is bound to both '%v' and '%v'",
+					child.prefix, addr, bind.addr))
+				continue
+			}
+			addrMap[child] = bind.addr
+		}
+	}
+	err = newBuildError(errs)
+	return
+}
+
+// bindDocsAddrMap returns the address serving the docs by the docs path,
+// the docs cannot be split when the routers of a docs path are served on the different addresses
+func bindDocsAddrMap(paths []*pathInfo, addrMap map[*RouterChild]string) (docsAddrMap map[string]string, err error) {
+	docsAddrMap = map[string]string{}
+	addrName := func(addr string) string {
+		if addr == "" {
+			return "the address of Run"
+		}
+		return "'" + addr + "'"
+	}
+	var errs []error
+	reported := map[string]struct{}{}
+	for _, path := range paths {
+		addr := addrMap[path.child]
+		if docsAddr, ok := docsAddrMap[path.docsPath]; ok && docsAddr != addr {
+			if _, ok = reported[path.docsPath]; ok {
+				continue
+			}
+			reported[path.docsPath] = struct{}{}
+			errs = append(errs, fmt.Errorf("the routers of the docs path '%v' are served on both %v and %v, "+
+				"the children sharing the docs path must be bound together, pos: %v",
+				path.docsPath, addrName(docsAddr), addrName(addr), path.pos))
+			continue
+		}
+		docsAddrMap[path.docsPath] = addr
+	}
+	err = newBuildError(errs)
+	return
+}
+
+// listenBinds listens on the bound addresses, the listeners are closed when any of them fails
+func (a *API) listenBinds(addrList []string) (listeners []net.Listener, err error) {
+	listen := a.listenBind
+	if listen == nil {
+		listen = func(addr string) (net.Listener, error) {
+			return net.Listen("tcp", addr)
+		}
+	}
+	for _, addr := range addrList {
+		var ln net.Listener
+		if ln, err = listen(addr); err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, ln)
+	}
+	return
+}
@@ -0,0 +1,603 @@
+package goapi
+
+import (
+	"encoding"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// bindPlan It is the binding plan of a router, compiled once when the server is built,
+// so that the kinds, the field indexes and the validators are not re-derived for each request
+type bindPlan struct {
+	inType   reflect.Type
+	formType MediaType
+	binders  []paramBinder
+	binder   inputBinder   // the binder registered by RegisterBinder, used instead of the binders except the dependencies
+	receiver reflect.Value // the receiver of the struct router, the method is called without binding it again
+	method   reflect.Value
+	depends  map[*provider][]paramBinder // the binders of the parameters of the providers
+}
+
+// paramBinder It sets and validates one parameter of the router input
+type paramBinder func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error
+
+// fieldValidator It validates the value of a paramField
+type fieldValidator func(h *handlerServer, ctx *Context, value reflect.Value) error
+
+// mediaTypeValidator It is the validator of the body compiled for a media type
+type mediaTypeValidator struct {
+	mediaType MediaType
+	validate  fieldValidator
+}
+
+func (h *handlerServer) compileBindPlan(path *pathInfo) *bindPlan {
+	plan := &bindPlan{
+		inType:   path.inTypes[len(path.inTypes)-1],
+		receiver: path.receiver,
+		method:   path.method,
+	}
+	if registered := getBinder(plan.inType); registered != nil {
+		if registered.tagHash == inputTagHash(plan.inType) {
+			plan.binder = registered.binder
+		} else if h.log != nil {
+			h.log.Warn("the tags of '%v' are changed after the binder is generated, it is bound by the reflection, "+
+				"run 'go generate' again, pos: %v", plan.inType, path.pos)
+		}
+	}
+	for _, in := range path.inParams {
+		if plan.binder != nil {
+			break
+		}
+		if in.inType == inTypeFile {
+			plan.formType = formMultipart
+		} else if in.inType == inTypeForm && plan.formType != formMultipart {
+			plan.formType = formUrlencoded
+		}
+	}
+	for _, in := range path.inParams {
+		switch {
+		case in.inType == inTypeDepend:
+			plan.binders = append(plan.binders, plan.compileDependBinder(in))
+		case in.depend != nil:
+			if plan.depends == nil {
+				plan.depends = map[*provider][]paramBinder{}
+			}
+			plan.depends[in.depend] = append(plan.depends[in.depend], h.compileBinder(in, plan.formType))
+		case plan.binder == nil:
+			plan.binders = append(plan.binders, h.compileBinder(in, plan.formType))
+		}
+	}
+	return plan
+}
+
+// bind It creates the input of the router and binds the request to it
+func (p *bindPlan) bind(h *handlerServer, ctx *Context, ctxVal reflect.Value) (value reflect.Value, err error) {
+	if p.binder != nil {
+		ptr := reflect.New(p.inType)
+		if err = p.binder(ctx, ptr); err != nil {
+			return ptr.Elem(), err
+		}
+		value = ptr.Elem()
+	} else {
+		value = reflect.New(p.inType).Elem()
+	}
+	switch p.formType {
+	case formUrlencoded:
+		if err = ctx.Request.ParseForm(); err != nil {
+			return
+		}
+	case formMultipart:
+		if err = ctx.Request.ParseMultipartForm(32 << 20); err != nil {
+			return
+		}
+	}
+	for _, binder := range p.binders {
+		if err = binder(h, ctx, ctxVal, value); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// compileDependBinder It sets the field by the value of the provider
+func (p *bindPlan) compileDependBinder(in *inParam) paramBinder {
+	getField := compileFieldGetter(in.deeps)
+	depend := in.depend
+	return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+		val, err := p.provide(h, ctx, depend)
+		if err != nil {
+			return err
+		}
+		getField(value).Set(val)
+		return nil
+	}
+}
+
+// provide It returns the value of the provider, the value of ScopeRequest is cached by the context
+// and the cleanup is executed after the router and the middlewares return
+func (p *bindPlan) provide(h *handlerServer, ctx *Context, depend *provider) (value reflect.Value, err error) {
+	if depend.scope == ScopeSingleton {
+		return depend.singleton(ctx.Logger())
+	}
+	if value, ok := ctx.provided[depend]; ok {
+		return value, nil
+	}
+	args := make([]reflect.Value, len(depend.args))
+	for k, arg := range depend.args {
+		switch arg.kind {
+		case providerArgCtx:
+			args[k] = reflect.ValueOf(ctx)
+		case providerArgDepend:
+			if args[k], err = p.provide(h, ctx, arg.depend); err != nil {
+				return
+			}
+		case providerArgParams:
+			args[k] = reflect.New(arg._type).Elem()
+			for _, binder := range p.depends[depend] {
+				if err = binder(h, ctx, reflect.Value{}, args[k]); err != nil {
+					return
+				}
+			}
+		}
+	}
+	value, cleanup, err := depend.call(ctx.Logger(), args)
+	if err != nil {
+		return
+	}
+	if cleanup != nil {
+		ctx.cleanups = append(ctx.cleanups, cleanup)
+	}
+	if ctx.provided == nil {
+		ctx.provided = map[*provider]reflect.Value{}
+	}
+	ctx.provided[depend] = value
+	return
+}
+
+// call It calls the router, the receiver of the struct router is passed as the first input
+func (p *bindPlan) call(value reflect.Value, inputs []reflect.Value) []reflect.Value {
+	if !p.receiver.IsValid() {
+		return value.Call(inputs)
+	}
+	return p.method.Call(inputs)
+}
+
+// compileFieldGetter It returns the function to get the field by the deeps, the pointers on the way are initialized
+func compileFieldGetter(deeps []int) func(value reflect.Value) reflect.Value {
+	switch len(deeps) {
+	case 0:
+		return func(value reflect.Value) reflect.Value {
+			return value
+		}
+	case 1:
+		index := deeps[0]
+		return func(value reflect.Value) reflect.Value {
+			for value.Kind() == reflect.Ptr {
+				initPtr(value)
+				value = value.Elem()
+			}
+			return value.Field(index)
+		}
+	}
+	deeps = append([]int(nil), deeps...)
+	return func(value reflect.Value) reflect.Value {
+		for _, index := range deeps {
+			for value.Kind() == reflect.Ptr {
+				initPtr(value)
+				value = value.Elem()
+			}
+			value = value.Field(index)
+		}
+		return value
+	}
+}
+
+func (h *handlerServer) compileBinder(in *inParam, formType MediaType) paramBinder {
+	getField := compileFieldGetter(in.deeps)
+	field := in.field
+	switch in.inType {
+	case inTypePath, inTypeHost:
+		name := in.values[0].name
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			inValue := getField(value)
+			if val, ok := ctx.Params.Get(name); ok {
+				return h.handleParamByString(ctx, inValue, field, val)
+			}
+			return nil
+		}
+	case inTypeQuery:
+		name := in.values[0].name
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			return h.handleParamByStringSlice(ctx, getField(value), field, ctx.Query()[name])
+		}
+	case inTypeHeader:
+		name := in.values[0].name
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			inValue := getField(value)
+			return h.handleParamByString(ctx, inValue, field, ctx.Request.Header.Get(name))
+		}
+	case inTypeCookie:
+		name := in.values[0].name
+		if field._type.ConvertibleTo(typeCookie) {
+			return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+				inValue := getField(value)
+				cookie, _ := ctx.Request.Cookie(name)
+				return h.handleParamByCookie(ctx, inValue, field, cookie)
+			}
+		}
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			inValue := getField(value)
+			val := ""
+			if cookie, _ := ctx.Request.Cookie(name); cookie != nil {
+				val = cookie.Value
+			}
+			return h.handleParamByString(ctx, inValue, field, val)
+		}
+	case inTypeForm:
+		name := in.values[0].name
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			inValue := getField(value)
+			val := ""
+			switch formType {
+			case formUrlencoded:
+				val = ctx.Request.Form.Get(name)
+			case formMultipart:
+				if ctx.Request.MultipartForm != nil && ctx.Request.MultipartForm.Value[name] != nil {
+					val = ctx.Request.MultipartForm.Value[name][0]
+				}
+			}
+			return h.handleParamByString(ctx, inValue, field, val)
+		}
+	case inTypeFile:
+		name := in.values[0].name
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			inValue := getField(value)
+			var files []*multipart.FileHeader
+			if ctx.Request.MultipartForm != nil {
+				files = ctx.Request.MultipartForm.File[name]
+			}
+			return h.handleParamByFields(ctx, inValue, field, files)
+		}
+	case inTypeBody:
+		return h.compileBodyBinder(in, getField)
+	case inTypeSecurityHTTPBearer:
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			inValue := getField(value)
+			initPtr(inValue)
+			authorization := ctx.Request.Header.Get("Authorization")
+			authType, token, _ := strings.Cut(authorization, " ")
+			if toFirstUpper(authType) != "Bearer" {
+				token = ""
+			}
+			inValueAny := inValue.Interface()
+			valOmitempty := false
+			if fn, ok := inValueAny.(SecurityOmitempty); ok {
+				valOmitempty = fn.Omitempty()
+			}
+			if !valOmitempty && token == "" {
+				return NewHTTPError(authErrorCode, ctx.lang().NotAuthenticated())
+			}
+			return inValueAny.(HTTPBearer).HTTPBearer(token)
+		}
+	case inTypeSecurityHTTPBearerJWT:
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			inValue := getField(value)
+			initPtr(inValue)
+			authorization := ctx.Request.Header.Get("Authorization")
+			authType, token, _ := strings.Cut(authorization, " ")
+			if toFirstUpper(authType) != "Bearer" {
+				token = ""
+			}
+			inValueAny := inValue.Interface()
+			security := inValueAny.(HTTPBearerJWT)
+			valOmitempty := false
+			if fn, ok := inValueAny.(SecurityOmitempty); ok {
+				valOmitempty = fn.Omitempty()
+			}
+			if token == "" {
+				if valOmitempty {
+					return security.HTTPBearerJWT(nil)
+				}
+				return NewHTTPError(authErrorCode, ctx.lang().NotAuthenticated())
+			}
+			jwt := &JWT{}
+			if err := decryptJWT(jwt, token, security); err != nil {
+				return NewHTTPError(authErrorCode, ctx.lang().JwtTranslate(err.Error()))
+			}
+			return security.HTTPBearerJWT(jwt)
+		}
+	case inTypeSecurityHTTPBasic:
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			inValue := getField(value)
+			initPtr(inValue)
+			username, password, _ := ctx.Request.BasicAuth()
+			inValueAny := inValue.Interface()
+			valOmitempty := false
+			if fn, ok := inValueAny.(SecurityOmitempty); ok {
+				valOmitempty = fn.Omitempty()
+			}
+			if !valOmitempty && username == "" {
+				return NewHTTPError(authErrorCode, ctx.lang().NotAuthenticated())
+			}
+			return inValueAny.(HTTPBasic).HTTPBasic(username, password)
+		}
+	case inTypeSecurityApiKey:
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			inValue := getField(value)
+			initPtr(inValue)
+			return inValue.Interface().(ApiKey).ApiKey()
+		}
+	case inTypeCtx:
+		return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+			h.handleParamByCtx(ctxVal, getField(value))
+			return nil
+		}
+	}
+	return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) error {
+		getField(value)
+		return nil
+	}
+}
+
+func (h *handlerServer) compileBodyBinder(in *inParam, getField func(value reflect.Value) reflect.Value) paramBinder {
+	field := in.field
+	validators := make([]mediaTypeValidator, 0, len(in.values))
+	for _, item := range in.values {
+		if item.mediaType.IsStream() {
+			continue
+		}
+		validators = append(validators, mediaTypeValidator{
+			mediaType: item.mediaType.MediaType(),
+			validate:  h.compileValidator(field, item.mediaType),
+		})
+	}
+	return func(h *handlerServer, ctx *Context, ctxVal, value reflect.Value) (err error) {
+		inValue := getField(value)
+		// the media type is normalized, such as the tag 'json' of 'application/json'
+		mediaType := h.getRequestMediaType(ctx).MediaType()
+		if !h.isBodyMediaTypeAllowed(mediaType, in.values) {
+			return NewHTTPError(http.StatusUnsupportedMediaType, http.StatusText(http.StatusUnsupportedMediaType))
+		}
+		if err = h.setBody(inValue, ctx.Request.Body, mediaType); err != nil {
+			return
+		}
+		if mediaType.IsStream() {
+			return
+		}
+		// the media type is allowed, so one of the validators matches
+		for _, v := range validators {
+			if v.mediaType == mediaType {
+				return v.validate(h, ctx, inValue)
+			}
+		}
+		return
+	}
+}
+
+// validatorCompiler It compiles the validators of the paramField tree for a media type,
+// the validators of the same field are shared so that the recursive structures are supported
+type validatorCompiler struct {
+	h         *handlerServer
+	mediaType MediaType
+	compiled  map[*paramField]*fieldValidator
+}
+
+func (h *handlerServer) compileValidator(field *paramField, mediaType MediaType) fieldValidator {
+	c := &validatorCompiler{
+		h:         h,
+		mediaType: mediaType,
+		compiled:  map[*paramField]*fieldValidator{},
+	}
+	return c.compile(field)
+}
+
+func (c *validatorCompiler) compile(field *paramField) fieldValidator {
+	if fn, ok := c.compiled[field]; ok {
+		if *fn != nil {
+			return *fn
+		}
+		// the field is being compiled, it is a recursive structure
+		return func(h *handlerServer, ctx *Context, value reflect.Value) error {
+			return (*fn)(h, ctx, value)
+		}
+	}
+	fn := new(fieldValidator)
+	c.compiled[field] = fn
+	*fn = c.build(field, fn)
+	return *fn
+}
+
+func (c *validatorCompiler) build(field *paramField, self *fieldValidator) fieldValidator {
+	name := field.names.getFieldName(c.mediaType)
+	desc := c.h.getDesc(name.name, field)
+	check := c.buildKind(field, desc)
+	return func(h *handlerServer, ctx *Context, value reflect.Value) (err error) {
+		if !field.anonymous {
+			if value.Kind() != reflect.Ptr {
+				if value.IsZero() {
+					if name.required {
+						return errors.New(ctx.lang().Required(desc))
+					}
+					if defaultSet(value, field.meta._default) {
+						return (*self)(h, ctx, value)
+					}
+					return
+				}
+			} else {
+				for value.Kind() == reflect.Ptr {
+					if value.IsNil() {
+						if name.required {
+							return errors.New(ctx.lang().Required(desc))
+						}
+						if defaultSet(value, field.meta._default) {
+							return (*self)(h, ctx, value)
+						}
+						return
+					}
+					if _, ok := getTypeByCovertInterface[TextInterface](value); ok {
+						break
+					}
+					value = value.Elem()
+				}
+			}
+		}
+		realValue := value
+		for value.Kind() == reflect.Ptr {
+			initPtr(value)
+			realValue = value
+			value = value.Elem()
+		}
+		if err = h.handleValidate(field, realValue); err != nil {
+			return
+		}
+		if check == nil {
+			return
+		}
+		return check(h, ctx, value)
+	}
+}
+
+func (c *validatorCompiler) buildKind(field *paramField, desc string) fieldValidator {
+	switch field.kind {
+	case reflect.Struct:
+		fields := field.fields
+		if field.pkgName != "" {
+			if sInfo := c.h.handle.structs[field.pkgName]; sInfo != nil {
+				fields = sInfo.fields
+			}
+		}
+		indexes := make([]int, len(fields))
+		children := make([]fieldValidator, len(fields))
+		for k, childField := range fields {
+			indexes[k] = childField.index
+			children[k] = c.compile(childField)
+		}
+		return func(h *handlerServer, ctx *Context, value reflect.Value) (err error) {
+			for k, child := range children {
+				if err = child(h, ctx, value.Field(indexes[k])); err != nil {
+					return
+				}
+			}
+			return
+		}
+	case reflect.Slice, reflect.Array:
+		item := c.compile(field.fields[0])
+		return func(h *handlerServer, ctx *Context, value reflect.Value) (err error) {
+			if field.meta.max != nil && uint64(value.Len()) > *field.meta.max {
+				return errors.New(ctx.lang().Max(desc, *field.meta.max))
+			}
+			if uint64(value.Len()) < field.meta.min {
+				return errors.New(ctx.lang().Min(desc, field.meta.min))
+			}
+			if field.meta.unique {
+				m := map[any]struct{}{}
+				for i := 0; i < value.Len(); i++ {
+					itemVal := value.Index(i)
+					if !itemVal.Comparable() {
+						continue
+					}
+					itemAny := itemVal.Interface()
+					if _, ok := m[itemAny]; ok {
+						return errors.New(ctx.lang().Unique(desc))
+					}
+					m[itemAny] = struct{}{}
+				}
+			}
+			for i := 0; i < value.Len(); i++ {
+				if err = item(h, ctx, value.Index(i)); err != nil {
+					return
+				}
+			}
+			return
+		}
+	case reflect.Map:
+		key := c.compile(field.fields[0])
+		elem := c.compile(field.fields[1])
+		return func(h *handlerServer, ctx *Context, value reflect.Value) (err error) {
+			if field.meta.max != nil && uint64(value.Len()) > *field.meta.max {
+				return errors.New(ctx.lang().Max(desc, *field.meta.max))
+			}
+			if uint64(value.Len()) < field.meta.min {
+				return errors.New(ctx.lang().Min(desc, field.meta.min))
+			}
+			for _, k := range value.MapKeys() {
+				if err = key(h, ctx, k); err != nil {
+					return
+				}
+				if err = elem(h, ctx, value.MapIndex(k)); err != nil {
+					return
+				}
+			}
+			return
+		}
+	case reflect.String:
+		return c.buildString(field, desc)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(h *handlerServer, ctx *Context, value reflect.Value) error {
+			return h.validFloat64(ctx, float64(value.Int()), desc, field)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(h *handlerServer, ctx *Context, value reflect.Value) error {
+			return h.validFloat64(ctx, float64(value.Uint()), desc, field)
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(h *handlerServer, ctx *Context, value reflect.Value) error {
+			return h.validFloat64(ctx, value.Float(), desc, field)
+		}
+	}
+	return nil
+}
+
+func (c *validatorCompiler) buildString(field *paramField, desc string) fieldValidator {
+	enum := field.meta.enum
+	// the enum of the text type is compared by the marshaled text,
+	// the error of the last marshaled enum is kept to be returned as before
+	var enumMarshaled bool
+	var enumErr error
+	if field.isTextType && enum != nil {
+		enum = make([]any, len(field.meta.enum))
+		copy(enum, field.meta.enum)
+		for k, v := range enum {
+			if fn, ok := getFnByCovertInterface[encoding.TextMarshaler](v); ok {
+				enumMarshaled = true
+				var txt []byte
+				if txt, enumErr = fn.MarshalText(); enumErr == nil {
+					enum[k] = string(txt)
+				}
+			}
+		}
+	}
+	re := c.h.getCompiledRegexp(field.meta.regexp)
+	return func(h *handlerServer, ctx *Context, value reflect.Value) (err error) {
+		valStr := ""
+		if field.isTextType {
+			if fn, ok := getFnByCovertInterface[encoding.TextMarshaler](value); ok {
+				var txt []byte
+				if txt, err = fn.MarshalText(); err == nil {
+					valStr = string(txt)
+				}
+			}
+			if enumMarshaled {
+				err = enumErr
+			}
+		} else {
+			valStr = value.String()
+		}
+		if field.meta.max != nil && uint64(len(valStr)) > *field.meta.max {
+			return errors.New(ctx.lang().Max(desc, *field.meta.max))
+		}
+		if uint64(len(valStr)) < field.meta.min {
+			return errors.New(ctx.lang().Min(desc, field.meta.min))
+		}
+		if re != nil && !re.MatchString(valStr) {
+			return errors.New(ctx.lang().Regexp(desc, field.meta.regexp))
+		}
+		if enum != nil && !inArrayAny(any(valStr), enum) {
+			return errors.New(ctx.lang().Enum(desc, enum))
+		}
+		return
+	}
+}
@@ -0,0 +1,61 @@
+package goapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bindPlanNode struct {
+	Name     string          `json:"name" max:"3"`
+	Children []*bindPlanNode `json:"children,omitempty"`
+}
+
+type bindPlanRouter struct {
+	prefix string
+}
+
+func (b *bindPlanRouter) Tree(input struct {
+	router Router        `paths:"/tree/{id}" methods:"POST"`
+	ID     int           `path:"id"`
+	Token  string        `header:"X-Token"`
+	Body   *bindPlanNode `body:"json"`
+}) string {
+	return b.prefix + input.Token + input.Body.Children[0].Name
+}
+
+func TestBindPlan(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	api.IncludeRouter(&bindPlanRouter{prefix: "p-"}, "", true)
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+		want        string
+	}{
+		{name: "nested", contentType: "application/json", body: `{"name":"a","children":[{"name":"b"}]}`, code: http.StatusOK, want: "p-tb"},
+		{name: "recursive validation", contentType: "application/json", body: `{"name":"a","children":[{"name":"long"}]}`, code: validErrorCode},
+		{name: "required", contentType: "application/json", body: `{"name":"a","children":[{}]}`, code: validErrorCode},
+		{name: "undeclared media type", contentType: "application/json; charset=utf-8", body: `{"name":"a","children":[{"name":"c"}]}`, code: http.StatusOK, want: "p-tc"},
+		{name: "media type tag", contentType: "json", body: `{"name":"a","children":[{"name":"d"}]}`, code: http.StatusOK, want: "p-td"},
+		{name: "media type tag validation", contentType: "json", body: `{"name":"a","children":[{"name":"long"}]}`, code: validErrorCode},
+		{name: "unsupported media type", contentType: "text/plain", body: `{}`, code: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/tree/1", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", tt.contentType)
+		r.Header.Set("X-Token", "t")
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%v: got %v %q want %v %q", tt.name, w.Code, w.Body.String(), tt.code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package goapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type bindTestRouter struct{}
+
+func (b *bindTestRouter) Metrics(input struct {
+	router Router `paths:"/metrics" methods:"GET"`
+}) string {
+	return "metrics"
+}
+
+func TestBindServesChildrenOnOtherAddress(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", true)
+	admin := api.Child("/admin", "/admin/docs")
+	admin.AddMiddleware(func(ctx *Context) {
+		ctx.Writer.Header().Set("X-Admin", "1")
+		ctx.Next()
+	})
+	admin.IncludeRouter(&bindTestRouter{}, "", true)
+	adminAddr := freeAddr(t)
+	api.Bind(adminAddr, admin)
+	addr := freeAddr(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.Run(addr)
+	}()
+	waitServing(t, addr)
+	waitServing(t, adminAddr)
+	tests := []struct {
+		url    string
+		status int
+		admin  bool
+	}{
+		{url: "http://" + addr + "/ping", status: http.StatusOK},
+		{url: "http://" + addr + "/docs/openapi.json", status: http.StatusOK},
+		{url: "http://" + addr + "/admin/metrics", status: http.StatusNotFound},
+		{url: "http://" + addr + "/docs/admin/docs/openapi.json", status: http.StatusNotFound},
+		{url: "http://" + adminAddr + "/admin/metrics", status: http.StatusOK, admin: true},
+		{url: "http://" + adminAddr + "/docs/admin/docs/openapi.json", status: http.StatusOK},
+		{url: "http://" + adminAddr + "/ping", status: http.StatusNotFound},
+	}
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	for _, tt := range tests {
+		resp, err := client.Get(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%v: got %v want %v", tt.url, resp.StatusCode, tt.status)
+		}
+		if tt.admin && resp.Header.Get("X-Admin") != "1" {
+			t.Errorf("%v: the middleware of the bound child was not executed", tt.url)
+		}
+	}
+	if err := api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run after Shutdown: got %v want nil", err)
+	}
+	resp, err := http.Get("http://" + adminAddr + "/admin/metrics")
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("the bound address should be shut down")
+	}
+}
+
+func TestBindSameChildToTwoAddresses(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	admin := api.Child("/admin", "/admin/docs")
+	api.Bind("127.0.0.1:9090", admin)
+	api.Bind("127.0.0.1:9091", admin)
+	_, err := api.Build()
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) || len(buildErr.Errors) != 1 {
+		t.Fatalf("Build error: got %v", err)
+	}
+}
+
+func TestBindChildrenSharingDocsPath(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	admin := api.Child("/admin", "/internal")
+	admin.IncludeRouter(&bindTestRouter{}, "", true)
+	ops := api.Child("/ops", "/internal")
+	ops.IncludeRouter(&bindTestRouter{}, "", true)
+	api.Bind("127.0.0.1:9090", admin)
+	_, err := api.Build()
+	if err == nil || !strings.Contains(err.Error(), "the children sharing the docs path must be bound together") {
+		t.Fatalf("Build error: got %v", err)
+	}
+	api.Bind("127.0.0.1:9090", ops)
+	if _, err = api.Build(); err != nil {
+		t.Fatalf("the children sharing the docs path can be bound to the same address, got %v", err)
+	}
+	api.Bind("127.0.0.1:9090", api.RouterChild)
+	if _, err = api.Build(); err == nil || !strings.Contains(err.Error(), "only the children created by API.Child can be bound") {
+		t.Fatalf("Build error: got %v", err)
+	}
+}
+
+func TestBindClosedBeforeShutdownHooks(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	admin := api.Child("/admin", "/admin/docs")
+	admin.IncludeRouter(&bindTestRouter{}, "", true)
+	adminAddr := freeAddr(t)
+	api.Bind(adminAddr, admin)
+	bindServing := make(chan bool, 1)
+	api.OnShutdown(func(ctx context.Context, log Logger) error {
+		conn, err := net.Dial("tcp", adminAddr)
+		if err == nil {
+			_ = conn.Close()
+		}
+		bindServing <- err == nil
+		return nil
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.RunListener(ln)
+	}()
+	waitServing(t, ln.Addr().String())
+	waitServing(t, adminAddr)
+	// the main server fails
+	_ = ln.Close()
+	if err = <-runErr; err == nil {
+		t.Fatal("RunListener should return the error of the closed listener")
+	}
+	if <-bindServing {
+		t.Fatal("the bound address should be closed before the OnShutdown hooks")
+	}
+}
+
+func TestBindReadyOnce(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	admin := api.Child("/admin", "/admin/docs")
+	admin.IncludeRouter(&bindTestRouter{}, "", true)
+	ops := api.Child("/ops", "/ops/docs")
+	ops.IncludeRouter(&bindTestRouter{}, "", true)
+	adminAddr, opsAddr := freeAddr(t), freeAddr(t)
+	api.Bind(adminAddr, admin)
+	api.Bind(opsAddr, ops)
+	addr := freeAddr(t)
+	var serves, readies int32
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.run("http", addr, func() (net.Listener, error) {
+			return net.Listen("tcp", addr)
+		}, func(server *http.Server, ln net.Listener) error {
+			atomic.AddInt32(&serves, 1)
+			return server.Serve(ln)
+		}, func() {
+			// all the listeners are open
+			for _, a := range []string{addr, adminAddr, opsAddr} {
+				if conn, err := net.Dial("tcp", a); err != nil {
+					t.Errorf("%v should be listened before ready, got %v", a, err)
+				} else {
+					_ = conn.Close()
+				}
+			}
+			atomic.AddInt32(&readies, 1)
+		})
+	}()
+	waitServing(t, addr)
+	waitServing(t, adminAddr)
+	waitServing(t, opsAddr)
+	if err := api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-runErr; err != nil {
+		t.Fatal(err)
+	}
+	if serves != 3 || readies != 1 {
+		t.Errorf("got %v serves and %v readies, want 3 and 1", serves, readies)
+	}
+}
@@ -0,0 +1,81 @@
+package goapi
+
+import (
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// binders the registered binders of the router inputs, map[reflect.Type]*registeredBinder
+var binders sync.Map
+
+// registeredBinder It is the binder registered by RegisterBinder and the hash of the tags it is generated from
+type registeredBinder struct {
+	binder  inputBinder
+	tagHash string
+}
+
+// inputBinder It binds the request to the input, the value is a pointer to the input
+type inputBinder func(ctx *Context, value reflect.Value) error
+
+// RegisterBinder It is to register the binder of the router input 'In', which is usually
+// called by the code generated by the goapi-gen command.
+// The routers whose input is 'In' are bound by the binder instead of the reflection,
+// so the binder must bind and validate all the parameters of 'In'.
+// It must be called before the API is built, such as in the init function.
+// The tagHash is the hash of the field names and the tags of 'In' when the binder is generated,
+// the routers are bound by the reflection with a warning if the tags are changed after that
+//
+// example:
+//
+//	//go:generate go run github.com/goodluckxu-go/goapi/v2/cmd/goapi-gen
+func RegisterBinder[In any](tagHash string, binder func(ctx *Context, in *In) error) {
+	binders.Store(reflect.TypeOf((*In)(nil)).Elem(), &registeredBinder{
+		binder: func(ctx *Context, value reflect.Value) error {
+			return binder(ctx, value.Interface().(*In))
+		},
+		tagHash: tagHash,
+	})
+}
+
+// getBinder returns the registered binder of the router input, nil if not registered
+func getBinder(inType reflect.Type) *registeredBinder {
+	if v, ok := binders.Load(inType); ok {
+		return v.(*registeredBinder)
+	}
+	return nil
+}
+
+// inputTagHash returns the hash of the field names and the tags of the input, including the exported
+// structures of the body, it is the same as the hash written by the goapi-gen command
+func inputTagHash(inType reflect.Type) string {
+	var b strings.Builder
+	visited := map[reflect.Type]struct{}{}
+	var walk func(sType reflect.Type, isBody bool)
+	walk = func(sType reflect.Type, isBody bool) {
+		for i := 0; i < sType.NumField(); i++ {
+			field := sType.Field(i)
+			b.WriteString(field.Name + " " + strconv.Quote(string(field.Tag)) + "\n")
+			if !field.IsExported() || (!isBody && field.Tag.Get(string(inTypeBody)) == "") {
+				continue
+			}
+			fType := field.Type
+			for fType.Kind() == reflect.Ptr || fType.Kind() == reflect.Slice || fType.Kind() == reflect.Array {
+				fType = fType.Elem()
+			}
+			if _, ok := visited[fType]; ok || fType.Kind() != reflect.Struct {
+				continue
+			}
+			visited[fType] = struct{}{}
+			b.WriteString("{\n")
+			walk(fType, true)
+			b.WriteString("}\n")
+		}
+	}
+	walk(inType, false)
+	h := fnv.New64a()
+	h.Write([]byte(b.String()))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -0,0 +1,80 @@
+package goapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type binderTestInput struct {
+	router Router `paths:"/binder" methods:"GET"`
+	Name   string `query:"name"`
+}
+
+type binderTestBody struct {
+	Name string `json:"name"`
+}
+
+type binderTestOtherBody struct {
+	Name string `json:"name" max:"5"`
+}
+
+type binderTestRouter struct{}
+
+func (b *binderTestRouter) Get(input binderTestInput) string {
+	return input.Name
+}
+
+func TestRegisterBinder(t *testing.T) {
+	inType := reflect.TypeOf(binderTestInput{})
+	defer binders.Delete(inType)
+	tests := []struct {
+		name    string
+		tagHash string
+		code    int
+		want    string
+	}{
+		// the 'name' is required by the reflection, the binder is used instead
+		{name: "generated", tagHash: inputTagHash(inType), code: http.StatusOK, want: `"bound:"`},
+		{name: "tags changed", tagHash: "0", code: validErrorCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RegisterBinder(tt.tagHash, func(ctx *Context, in *binderTestInput) error {
+				in.Name = "bound:" + ctx.Query().Get("name")
+				return nil
+			})
+			api := New(true)
+			log := &warnTestLogger{}
+			api.SetLogger(log)
+			api.IncludeRouter(&binderTestRouter{}, "", true)
+			handler, err := api.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/binder", nil))
+			if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("got %v %q want %v %q", w.Code, w.Body.String(), tt.code, tt.want)
+			}
+			warned := len(log.warns) == 1 && strings.Contains(log.warns[0], "run 'go generate' again")
+			if warned != (tt.code != http.StatusOK) {
+				t.Errorf("got the warnings %q", log.warns)
+			}
+		})
+	}
+}
+
+func TestInputTagHash(t *testing.T) {
+	a := inputTagHash(reflect.TypeOf(struct {
+		Body *binderTestBody `body:"json"`
+	}{}))
+	b := inputTagHash(reflect.TypeOf(struct {
+		Body *binderTestOtherBody `body:"json"`
+	}{}))
+	if a == b {
+		t.Errorf("the hash should contain the tags of the body, got %v", a)
+	}
+}
@@ -0,0 +1,161 @@
+package goapi
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultCertWatchInterval It is the interval of Watch when the interval is not positive
+const defaultCertWatchInterval = time.Minute
+
+// NewCertReloader It is to load the certificate, and reload it when the files change or SIGHUP is received
+//
+// example:
+//
+//	reloader, err := goapi.NewCertReloader("server.crt", "server.key")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	go reloader.Watch(context.Background(), time.Minute)
+//	api.Server(goapi.ServerConfig{
+//		TLSConfig: &tls.Config{
+//			MinVersion:     tls.VersionTLS12,
+//			GetCertificate: reloader.GetCertificate,
+//		},
+//	})
+//	log.Fatal(api.RunTLS(":443", "", ""))
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	c := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CertReloader It is a certificate loader that can be reloaded while serving
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	log      Logger
+	mux      sync.RWMutex
+	cert     *tls.Certificate
+	certStat certFileStat
+	keyStat  certFileStat
+}
+
+type certFileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// SetLogger It is a function for setting the logs of reloading
+func (c *CertReloader) SetLogger(log Logger) {
+	c.mux.Lock()
+	c.log = log
+	c.mux.Unlock()
+}
+
+// GetCertificate It is used by tls.Config.GetCertificate, returns the latest loaded certificate
+func (c *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.cert, nil
+}
+
+// Reload It is to load the certificate files again, the previous certificate is kept when it fails
+func (c *CertReloader) Reload() error {
+	certStat, err := statCertFile(c.certFile)
+	if err != nil {
+		return err
+	}
+	keyStat, err := statCertFile(c.keyFile)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate failed: %w", err)
+	}
+	c.mux.Lock()
+	c.cert = &cert
+	c.certStat = certStat
+	c.keyStat = keyStat
+	c.mux.Unlock()
+	return nil
+}
+
+// Watch It is to check the files every interval and reload the certificate when they change,
+// the interval is one minute when it is not positive. SIGHUP also triggers a reload. It blocks until ctx is done
+func (c *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultCertWatchInterval
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			c.reload("SIGHUP")
+		case <-ticker.C:
+			if c.changed() {
+				c.reload("file change")
+			}
+		}
+	}
+}
+
+func (c *CertReloader) reload(reason string) {
+	err := c.Reload()
+	c.mux.RLock()
+	log := c.log
+	c.mux.RUnlock()
+	if log == nil {
+		return
+	}
+	if err != nil {
+		log.Error("Reload certificate on %v failed: %v", reason, err)
+		return
+	}
+	log.Info("Reloaded certificate on %v", reason)
+}
+
+func (c *CertReloader) changed() bool {
+	certStat, err := statCertFile(c.certFile)
+	if err != nil {
+		return false
+	}
+	keyStat, err := statCertFile(c.keyFile)
+	if err != nil {
+		return false
+	}
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return !certStat.equal(c.certStat) || !keyStat.equal(c.keyStat)
+}
+
+func (c certFileStat) equal(stat certFileStat) bool {
+	return c.modTime.Equal(stat.modTime) && c.size == stat.size
+}
+
+func statCertFile(name string) (stat certFileStat, err error) {
+	// os.Stat follows symbolic links, so that the replaced link targets are detected
+	info, err := os.Stat(name)
+	if err != nil {
+		return
+	}
+	return certFileStat{modTime: info.ModTime(), size: info.Size()}, nil
+}
@@ -0,0 +1,176 @@
+package goapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestCert(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, "server.crt")
+	keyFile = filepath.Join(dir, "server.key")
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func certCommonName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloaderWatchReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "first")
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+
+	// make sure the modification time changes on file systems with a coarse resolution
+	time.Sleep(20 * time.Millisecond)
+	writeTestCert(t, dir, "second")
+	future := time.Now().Add(time.Second)
+	_ = os.Chtimes(certFile, future, future)
+	for i := 0; i < 100; i++ {
+		cert, _ := reloader.GetCertificate(nil)
+		if certCommonName(t, cert) == "second" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the certificate was not reloaded after the files changed")
+}
+
+func TestCertReloaderWatchZeroInterval(t *testing.T) {
+	certFile, keyFile := writeTestCert(t, t.TempDir(), "first")
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// the interval of the zero-value configuration is the default
+		reloader.Watch(ctx, 0)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Watch should return when the ctx is done")
+	}
+}
+
+func TestCertReloaderKeepsCertificateOnError(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "first")
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, []byte("broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = reloader.Reload(); err == nil {
+		t.Fatal("Reload should fail with a broken key")
+	}
+	cert, _ := reloader.GetCertificate(nil)
+	if certCommonName(t, cert) != "first" {
+		t.Fatal("the previous certificate should be kept")
+	}
+}
+
+func TestRunTLSUsesTLSConfig(t *testing.T) {
+	certFile, keyFile := writeTestCert(t, t.TempDir(), "localhost")
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	api.Server(ServerConfig{
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS13,
+			GetCertificate: reloader.GetCertificate,
+		},
+		Configure: func(server *http.Server) {
+			server.ErrorLog = log.New(io.Discard, "", 0)
+		},
+	})
+	addr := freeAddr(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.RunTLS(addr, "", "")
+	}()
+	waitServing(t, addr)
+	client := func(maxVersion uint16) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			MaxVersion:         maxVersion,
+		}}}
+	}
+	resp, err := client(tls.VersionTLS13).Get("https://" + addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status: got %v", resp.StatusCode)
+	}
+	if _, err = client(tls.VersionTLS12).Get("https://" + addr + "/ping"); err == nil {
+		t.Fatal("TLS 1.2 should be rejected by MinVersion")
+	}
+	if err = api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-runErr; err != nil {
+		t.Fatalf("RunTLS after Shutdown: got %v want nil", err)
+	}
+}
@@ -0,0 +1,1211 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"hash/fnv"
+	"math"
+	"net/textproto"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const goapiPath = "github.com/goodluckxu-go/goapi/v2"
+
+// metaMethods the methods which change the binding or the validation of a type, such as goapi.MetaEnum,
+// goapi.MetaValidate and encoding.TextUnmarshaler, the types with them are bound by the reflection
+var metaMethods = []string{"Regexp", "Enum", "Lt", "Lte", "Gt", "Gte", "Multiple", "Max", "Min", "Unique", "Desc",
+	"Default", "Example", "Deprecated", "Name", "Validate", "MarshalText", "UnmarshalText",
+	"HTTPBearer", "HTTPBearerJWT", "HTTPBasic", "ApiKey"}
+
+// inTags the tags of the parameters, see goapi.InType
+var inTags = []string{"header", "cookie", "path", "host", "query", "form", "file", "body"}
+
+var scalarKinds = map[string]bool{
+	"string": true, "bool": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "byte": true, "rune": true,
+}
+
+type generator struct {
+	fset        *token.FileSet
+	pkgName     string
+	files       []*ast.File
+	structs     map[string]*ast.StructType
+	structFiles map[string]*ast.File
+	types       map[string]struct{}            // all the named types of the package
+	methods     map[string]map[string]struct{} // the methods by the type name
+	// generated
+	imports     map[string]string // the import path by the name
+	registers   []register
+	binders     []string
+	validators  []string
+	validated   map[string]struct{}
+	regexps     []string
+	regexpNames map[string]string
+	inputs      map[string]struct{}
+	skips       []string
+	cur         *genState
+}
+
+// register It is the registration of a binder in the init function
+type register struct {
+	name    string
+	tagHash string
+}
+
+// genState It is the state of generating an input, which is dropped if the input is not supported
+type genState struct {
+	imports     map[string]string
+	validators  []string
+	validating  map[string]struct{}
+	regexps     []string
+	regexpNames map[string]string
+}
+
+// input It is the input of a router
+type input struct {
+	name  string // the name of the binder
+	typ   string // the type expression of the input
+	st    *ast.StructType
+	goapi string // the name of goapi imported by the file of the input
+	pos   string
+}
+
+// typeInfo It is the type of a field in the body
+type typeInfo struct {
+	ptr  bool
+	kind string // the builtin kind, 'struct' for the structures of the package and 'slice'
+	name string // the name of the structure
+	elem *typeInfo
+}
+
+// fieldMeta It is the validation of a field, see the tags of goapi
+type fieldMeta struct {
+	desc     string
+	def      string
+	hasDef   bool
+	enum     []string
+	lt       *float64
+	lte      *float64
+	gt       *float64
+	gte      *float64
+	max      *uint64
+	min      uint64
+	regexp   string
+	required bool
+}
+
+func newGenerator() *generator {
+	return &generator{
+		fset:        token.NewFileSet(),
+		structs:     map[string]*ast.StructType{},
+		structFiles: map[string]*ast.File{},
+		types:       map[string]struct{}{},
+		methods:     map[string]map[string]struct{}{},
+		imports:     map[string]string{},
+		validated:   map[string]struct{}{},
+		regexpNames: map[string]string{},
+		inputs:      map[string]struct{}{},
+	}
+}
+
+// parseDir parses the package in the directory, the test files and the output file are excluded
+func (g *generator) parseDir(dir, output string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		base := filepath.Base(path)
+		if strings.HasSuffix(base, "_test.go") || base == output {
+			continue
+		}
+		f, err := parser.ParseFile(g.fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		if g.pkgName == "" {
+			g.pkgName = f.Name.Name
+		} else if g.pkgName != f.Name.Name {
+			return fmt.Errorf("found the packages %v and %v in %v", g.pkgName, f.Name.Name, dir)
+		}
+		g.addFile(f)
+	}
+	if len(g.files) == 0 {
+		return fmt.Errorf("no go files in %v", dir)
+	}
+	return nil
+}
+
+func (g *generator) addFile(f *ast.File) {
+	g.files = append(g.files, f)
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				g.types[ts.Name.Name] = struct{}{}
+				if st, ok := ts.Type.(*ast.StructType); ok && ts.TypeParams == nil && !ts.Assign.IsValid() {
+					g.structs[ts.Name.Name] = st
+					g.structFiles[ts.Name.Name] = f
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) != 1 {
+				continue
+			}
+			name := recvName(d.Recv.List[0].Type)
+			if g.methods[name] == nil {
+				g.methods[name] = map[string]struct{}{}
+			}
+			g.methods[name][d.Name.Name] = struct{}{}
+		}
+	}
+}
+
+// generate returns the source of the binders of the routers and the named structures
+func (g *generator) generate(types []string) ([]byte, error) {
+	for _, f := range g.files {
+		alias := goapiName(f)
+		if alias == "" {
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || !fn.Name.IsExported() {
+				continue
+			}
+			if in, ok := g.routerInput(fn, alias); ok {
+				g.addInput(in)
+			}
+		}
+	}
+	for _, name := range types {
+		name = strings.TrimSpace(name)
+		st := g.structs[name]
+		if st == nil {
+			return nil, fmt.Errorf("the structure %v is not found in the package %v", name, g.pkgName)
+		}
+		g.addInput(input{name: "goapiBind" + name, typ: name, st: st, goapi: goapiName(g.structFiles[name]), pos: name})
+	}
+	return g.source()
+}
+
+// routerInput returns the input of the router method, such as 'func (r *User) Get(ctx *goapi.Context, in GetUser)'
+func (g *generator) routerInput(fn *ast.FuncDecl, alias string) (in input, ok bool) {
+	var params []ast.Expr
+	for _, field := range fn.Type.Params.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			params = append(params, field.Type)
+		}
+	}
+	switch len(params) {
+	case 1:
+	case 2:
+		if !isStarSel(params[0], alias, "Context") {
+			return
+		}
+	default:
+		return
+	}
+	recv := recvName(fn.Recv.List[0].Type)
+	in.pos = fmt.Sprintf("%v.%v", recv, fn.Name.Name)
+	switch t := params[len(params)-1].(type) {
+	case *ast.Ident:
+		if in.st = g.structs[t.Name]; in.st == nil {
+			return
+		}
+		in.name = "goapiBind" + t.Name
+		in.typ = t.Name
+		if in.goapi = goapiName(g.structFiles[t.Name]); in.goapi == "" {
+			return
+		}
+	case *ast.StructType:
+		in.st = t
+		in.name = "goapiBind" + recv + fn.Name.Name
+		in.goapi = alias
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, g.fset, t); err != nil {
+			return
+		}
+		in.typ = buf.String()
+	default:
+		return
+	}
+	for _, field := range in.st.Fields.List {
+		if isSel(field.Type, in.goapi, "Router") {
+			return in, true
+		}
+	}
+	return
+}
+
+func (g *generator) addInput(in input) {
+	if _, ok := g.inputs[in.typ]; ok {
+		return
+	}
+	g.inputs[in.typ] = struct{}{}
+	g.cur = &genState{
+		imports:     map[string]string{},
+		validating:  map[string]struct{}{},
+		regexpNames: map[string]string{},
+	}
+	code, err := g.genBinder(in)
+	if err != nil {
+		g.skips = append(g.skips, fmt.Sprintf("skip %v: %v, it is bound by the reflection", in.pos, err))
+		return
+	}
+	for name, path := range g.cur.imports {
+		g.imports[name] = path
+	}
+	for name := range g.cur.validating {
+		g.validated[name] = struct{}{}
+	}
+	for expr, name := range g.cur.regexpNames {
+		g.regexpNames[expr] = name
+	}
+	g.validators = append(g.validators, g.cur.validators...)
+	g.regexps = append(g.regexps, g.cur.regexps...)
+	g.registers = append(g.registers, register{name: in.name, tagHash: g.tagHash(in.st)})
+	g.binders = append(g.binders, code)
+}
+
+// tagHash returns the hash of the field names and the tags of the input, including the exported
+// structures of the body, it must be the same as the hash computed by goapi when the API is built
+func (g *generator) tagHash(st *ast.StructType) string {
+	var b strings.Builder
+	visited := map[string]struct{}{}
+	var walk func(st *ast.StructType, isBody bool)
+	walk = func(st *ast.StructType, isBody bool) {
+		for _, field := range st.Fields.List {
+			tag := fieldTag(field)
+			for _, ident := range field.Names {
+				b.WriteString(ident.Name + " " + strconv.Quote(string(tag)) + "\n")
+				if !ident.IsExported() || (!isBody && tag.Get("body") == "") {
+					continue
+				}
+				name := structName(field.Type)
+				if _, ok := visited[name]; ok || g.structs[name] == nil {
+					continue
+				}
+				visited[name] = struct{}{}
+				b.WriteString("{\n")
+				walk(g.structs[name], true)
+				b.WriteString("}\n")
+			}
+		}
+	}
+	walk(st, false)
+	h := fnv.New64a()
+	h.Write([]byte(b.String()))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// structName returns the name of the structure of the pointers, the slices and the arrays
+func structName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ArrayType:
+			expr = e.Elt
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func (g *generator) use(name, path string) {
+	g.cur.imports[name] = path
+}
+
+func (g *generator) genBinder(in input) (string, error) {
+	g.use("goapi", goapiPath)
+	if in.goapi != "goapi" {
+		if in.goapi == "." || in.goapi == "_" {
+			return "", fmt.Errorf("the import '%v' of goapi is not supported", in.goapi)
+		}
+		g.use(in.goapi, goapiPath)
+	}
+	var body, form strings.Builder
+	for _, field := range in.st.Fields.List {
+		if len(field.Names) == 0 {
+			return "", fmt.Errorf("the embedded field %v is not supported", exprString(field.Type))
+		}
+		tag := fieldTag(field)
+		for _, ident := range field.Names {
+			code, isForm, err := g.genInputField(ident.Name, field.Type, tag, in.goapi)
+			if err != nil {
+				return "", err
+			}
+			if isForm && form.Len() == 0 {
+				form.WriteString("if err := ctx.Request.ParseForm(); err != nil {\nreturn err\n}\n")
+			}
+			body.WriteString(code)
+		}
+	}
+	return fmt.Sprintf("func %v(ctx *goapi.Context, in *%v) error {\n%v%vreturn nil\n}\n", in.name, in.typ,
+		form.String(), body.String()), nil
+}
+
+func (g *generator) genInputField(name string, expr ast.Expr, tag reflect.StructTag, alias string) (code string, isForm bool, err error) {
+	if isSel(expr, alias, "Router") {
+		return
+	}
+	inTag := ""
+	for _, t := range inTags {
+		if tag.Get(t) == "" {
+			continue
+		}
+		if inTag != "" {
+			err = fmt.Errorf("the field %v has both '%v' and '%v'", name, inTag, t)
+			return
+		}
+		inTag = t
+	}
+	switch inTag {
+	case "":
+		if isStarSel(expr, alias, "Context") {
+			code = fmt.Sprintf("in.%v = ctx\n", name)
+			return
+		}
+		// it is filled by the provider of API.Provide after the binder
+		if isStarSel(expr, alias, "BackgroundTasks") {
+			return
+		}
+		if !g.isIgnored(expr) {
+			err = fmt.Errorf("the field %v of the type %v is not supported", name, exprString(expr))
+		}
+		return
+	case "file":
+		err = fmt.Errorf("the file %v is not supported", name)
+		return
+	case "body":
+		code, err = g.genBody(name, expr, tag)
+		return
+	}
+	code, err = g.genParam(name, expr, tag, inTag)
+	return code, inTag == "form", err
+}
+
+// isIgnored returns whether the field without the tags is ignored by goapi, the structures and
+// the securities are not ignored
+func (g *generator) isIgnored(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		_, ok := g.types[t.Name]
+		return !ok && (scalarKinds[t.Name] || t.Name == "error" || t.Name == "any")
+	case *ast.StarExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			_, ok = g.types[ident.Name]
+			return !ok && scalarKinds[ident.Name]
+		}
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType:
+		return true
+	}
+	return false
+}
+
+// genParam generates the parameter of path, host, query, header, cookie and form, see handlerServer.handleParamByString
+func (g *generator) genParam(name string, expr ast.Expr, tag reflect.StructTag, inTag string) (string, error) {
+	valSplit := strings.Split(tag.Get(inTag), ",")
+	paramName := valSplit[0]
+	if inTag == "header" {
+		paramName = textproto.CanonicalMIMEHeaderKey(paramName)
+	}
+	required := true
+	for _, v := range valSplit[1:] {
+		if v == "omitempty" {
+			required = false
+		}
+	}
+	kind, ptr := g.scalarKind(expr)
+	if kind == "" {
+		return "", fmt.Errorf("the type %v of the parameter %v is not supported", exprString(expr), name)
+	}
+	fallback := paramName
+	if fallback == "" {
+		fallback = name
+	}
+	m, err := g.parseMeta(tag, kind, fallback, true)
+	if err != nil {
+		return "", fmt.Errorf("the parameter %v: %v", name, err)
+	}
+	m.required = required
+	target := "in." + name
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %v\n", name)
+	switch inTag {
+	case "path", "host":
+		fmt.Fprintf(&b, "if val, ok := ctx.Params.Get(%q); ok {\n", paramName)
+	case "query":
+		fmt.Fprintf(&b, "{\nval := \"\"\nif values := ctx.Query()[%q]; len(values) > 0 {\nval = values[0]\n}\n", paramName)
+	case "header":
+		fmt.Fprintf(&b, "{\nval := ctx.Request.Header.Get(%q)\n", paramName)
+	case "cookie":
+		fmt.Fprintf(&b, "{\nval := \"\"\nif cookie, err := ctx.Request.Cookie(%q); err == nil {\nval = cookie.Value\n}\n", paramName)
+	case "form":
+		fmt.Fprintf(&b, "{\nval := ctx.Request.Form.Get(%q)\n", paramName)
+	}
+	nonEmpty := m.required || m.hasDef
+	if nonEmpty {
+		b.WriteString("if val == \"\" {\n")
+		if m.required {
+			g.use("errors", "errors")
+			fmt.Fprintf(&b, "return errors.New(ctx.Lang().Required(%q))\n", m.desc)
+		} else {
+			fmt.Fprintf(&b, "val = %q\n", m.def)
+		}
+		b.WriteString("}\n")
+	} else {
+		b.WriteString("if val != \"\" {\n")
+	}
+	if ptr {
+		fmt.Fprintf(&b, "if %v == nil {\n%v = new(%v)\n}\n", target, target, kind)
+		target = "*" + target
+	}
+	switch {
+	case kind == "string":
+		g.stringChecks(&b, "val", m)
+		fmt.Fprintf(&b, "%v = val\n", target)
+	case kind == "bool":
+		g.use("strconv", "strconv")
+		b.WriteString("v, err := strconv.ParseBool(val)\nif err != nil {\nreturn err\n}\n")
+		g.enumCheck(&b, "v", m)
+		fmt.Fprintf(&b, "%v = v\n", target)
+	default:
+		g.use("strconv", "strconv")
+		switch {
+		case isIntKind(kind):
+			b.WriteString("v, err := strconv.ParseInt(val, 10, 64)\n")
+		case isUintKind(kind):
+			b.WriteString("v, err := strconv.ParseUint(val, 10, 64)\n")
+		default:
+			b.WriteString("v, err := strconv.ParseFloat(val, 64)\n")
+		}
+		b.WriteString("if err != nil {\nreturn err\n}\n")
+		// When using required keys and zero values, please use Pointers
+		if m.required {
+			g.use("errors", "errors")
+			fmt.Fprintf(&b, "if v == 0 {\nreturn errors.New(ctx.Lang().Required(%q))\n}\n", m.desc)
+		} else {
+			b.WriteString("if v != 0 {\n")
+		}
+		g.numberChecks(&b, "v", m)
+		fmt.Fprintf(&b, "%v = %v(v)\n", target, kind)
+		if !m.required {
+			b.WriteString("}\n")
+		}
+	}
+	if !nonEmpty {
+		b.WriteString("}\n")
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// genBody generates the body of json, see handlerServer.setBody
+func (g *generator) genBody(name string, expr ast.Expr, tag reflect.StructTag) (string, error) {
+	for _, v := range strings.Split(tag.Get("body"), ",") {
+		if v != "json" && v != "application/json" {
+			return "", fmt.Errorf("the body %v of '%v' is not supported", name, v)
+		}
+	}
+	t, err := g.parseType(expr, false)
+	if err != nil {
+		return "", err
+	}
+	if t.kind != "struct" {
+		return "", fmt.Errorf("the body %v of the type %v is not supported", name, exprString(expr))
+	}
+	validator, err := g.validator(t.name)
+	if err != nil {
+		return "", err
+	}
+	g.use("strings", "strings")
+	g.use("http", "net/http")
+	g.use("reflect", "reflect")
+	target := "in." + name
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %v\n", name)
+	b.WriteString("{\nmediaType, _, _ := strings.Cut(ctx.Request.Header.Get(\"Content-Type\"), \";\")\n")
+	b.WriteString("if goapi.MediaType(mediaType).MediaType() != goapi.JSON.MediaType() {\n")
+	b.WriteString("return goapi.NewHTTPError(http.StatusUnsupportedMediaType, http.StatusText(http.StatusUnsupportedMediaType))\n}\n")
+	ptr := target
+	if t.ptr {
+		fmt.Fprintf(&b, "if %v == nil {\n%v = new(%v)\n}\n", target, target, t.name)
+	} else {
+		ptr = "&" + target
+	}
+	fmt.Fprintf(&b, "if ctx.Request.Body != nil {\nif err := goapi.JSON.Unmarshaler(ctx.Request.Body, reflect.ValueOf(%v)); err != nil {\nreturn err\n}\n}\n", ptr)
+	fmt.Fprintf(&b, "if err := %v(ctx, %v); err != nil {\nreturn err\n}\n}\n", validator, ptr)
+	return b.String(), nil
+}
+
+// validator generates the validator of the structure in the body, see handlerServer.validParamField
+func (g *generator) validator(name string) (fn string, err error) {
+	fn = "goapiValidate" + name
+	if _, ok := g.validated[name]; ok {
+		return
+	}
+	if _, ok := g.cur.validating[name]; ok {
+		return
+	}
+	st := g.structs[name]
+	if st == nil {
+		err = fmt.Errorf("the type %v is not supported", name)
+		return
+	}
+	if method := g.metaMethod(name); method != "" {
+		err = fmt.Errorf("the type %v with the method %v is not supported", name, method)
+		return
+	}
+	g.cur.validating[name] = struct{}{}
+	var b strings.Builder
+	fmt.Fprintf(&b, "func %v(ctx *goapi.Context, v *%v) error {\n", fn, name)
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			err = fmt.Errorf("the embedded field %v of %v is not supported", exprString(field.Type), name)
+			return
+		}
+		tag := fieldTag(field)
+		for _, ident := range field.Names {
+			if ident.Name[0] < 'A' || ident.Name[0] > 'Z' {
+				continue
+			}
+			if err = g.genBodyField(&b, "v."+ident.Name, ident.Name, field.Type, tag); err != nil {
+				err = fmt.Errorf("the field %v.%v: %v", name, ident.Name, err)
+				return
+			}
+		}
+	}
+	b.WriteString("return nil\n}\n")
+	g.cur.validators = append(g.cur.validators, b.String())
+	return
+}
+
+func (g *generator) genBodyField(b *strings.Builder, target, name string, expr ast.Expr, tag reflect.StructTag) error {
+	jsonSplit := strings.Split(tag.Get("json"), ",")
+	jsonName := jsonSplit[0]
+	if jsonName == "-" {
+		return fmt.Errorf("the json name '-' is not supported")
+	}
+	if jsonName == "" {
+		jsonName = name
+	}
+	required := true
+	for _, v := range jsonSplit[1:] {
+		if v == "omitempty" {
+			required = false
+		}
+	}
+	t, err := g.parseType(expr, true)
+	if err != nil {
+		return err
+	}
+	kind := t.kind
+	if t.kind == "struct" {
+		if _, err = g.validator(t.name); err != nil {
+			return err
+		}
+	}
+	m, err := g.parseMeta(tag, kind, jsonName, false)
+	if err != nil {
+		return err
+	}
+	m.required = required
+	if m.hasDef && (t.ptr || kind == "struct" || kind == "slice") {
+		return fmt.Errorf("the default of the type %v is not supported", exprString(expr))
+	}
+	switch {
+	case kind == "slice":
+		g.requiredCheck(b, target, "nil", m)
+		fmt.Fprintf(b, "if %v != nil {\n", target)
+		g.lenChecks(b, fmt.Sprintf("len(%v)", target), m)
+		fmt.Fprintf(b, "for i := range %v {\n", target)
+		if err = g.genItem(b, target+"[i]", t.elem); err != nil {
+			return err
+		}
+		b.WriteString("}\n}\n")
+	case kind == "struct":
+		zero := "nil"
+		ptr := target
+		if !t.ptr {
+			if !g.isComparable(t.name, map[string]bool{}) {
+				return fmt.Errorf("the structure %v which is not comparable is not supported", t.name)
+			}
+			zero = fmt.Sprintf("(%v{})", t.name)
+			ptr = "&" + target
+		}
+		g.requiredCheck(b, target, zero, m)
+		fmt.Fprintf(b, "if %v != %v {\nif err := goapiValidate%v(ctx, %v); err != nil {\nreturn err\n}\n}\n",
+			target, zero, t.name, ptr)
+	case t.ptr:
+		g.requiredCheck(b, target, "nil", m)
+		fmt.Fprintf(b, "if %v != nil {\n", target)
+		g.scalarChecks(b, "*"+target, kind, m)
+		b.WriteString("}\n")
+	default:
+		zero := zeroValue(kind)
+		nonZero := m.required || m.hasDef
+		if nonZero {
+			fmt.Fprintf(b, "if %v == %v {\n", target, zero)
+			if m.required {
+				g.use("errors", "errors")
+				fmt.Fprintf(b, "return errors.New(ctx.Lang().Required(%q))\n", m.desc)
+			} else {
+				fmt.Fprintf(b, "%v = %v\n", target, m.def)
+			}
+			b.WriteString("}\n")
+		} else {
+			fmt.Fprintf(b, "if %v != %v {\n", target, zero)
+		}
+		g.scalarChecks(b, target, kind, m)
+		if !nonZero {
+			b.WriteString("}\n")
+		}
+	}
+	return nil
+}
+
+// genItem generates the item of the slice, the item is required and its name is the name of the type
+func (g *generator) genItem(b *strings.Builder, target string, t *typeInfo) error {
+	m := fieldMeta{desc: t.kind, required: true}
+	switch t.kind {
+	case "struct":
+		m.desc = t.name
+	case "byte":
+		m.desc = "uint8"
+	case "rune":
+		m.desc = "int32"
+	}
+	applyKindBounds(t.kind, &m)
+	switch {
+	case t.kind == "struct":
+		if _, err := g.validator(t.name); err != nil {
+			return err
+		}
+		zero := "nil"
+		ptr := target
+		if !t.ptr {
+			if !g.isComparable(t.name, map[string]bool{}) {
+				return fmt.Errorf("the structure %v which is not comparable is not supported", t.name)
+			}
+			zero = fmt.Sprintf("(%v{})", t.name)
+			ptr = "&" + target
+		}
+		g.requiredCheck(b, target, zero, m)
+		fmt.Fprintf(b, "if err := goapiValidate%v(ctx, %v); err != nil {\nreturn err\n}\n", t.name, ptr)
+	case t.ptr:
+		g.requiredCheck(b, target, "nil", m)
+		g.scalarChecks(b, "*"+target, t.kind, m)
+	default:
+		g.requiredCheck(b, target, zeroValue(t.kind), m)
+		g.scalarChecks(b, target, t.kind, m)
+	}
+	return nil
+}
+
+func (g *generator) requiredCheck(b *strings.Builder, target, zero string, m fieldMeta) {
+	if !m.required {
+		return
+	}
+	g.use("errors", "errors")
+	fmt.Fprintf(b, "if %v == %v {\nreturn errors.New(ctx.Lang().Required(%q))\n}\n", target, zero, m.desc)
+}
+
+func (g *generator) scalarChecks(b *strings.Builder, target, kind string, m fieldMeta) {
+	switch {
+	case kind == "string":
+		g.stringChecks(b, target, m)
+	case kind == "bool":
+	default:
+		g.numberChecks(b, target, m)
+	}
+}
+
+// stringChecks see the string of handlerServer.validParamField
+func (g *generator) stringChecks(b *strings.Builder, target string, m fieldMeta) {
+	g.lenChecks(b, fmt.Sprintf("len(%v)", target), m)
+	if m.regexp != "" {
+		if _, err := regexp.Compile(m.regexp); err == nil {
+			g.use("errors", "errors")
+			fmt.Fprintf(b, "if !%v.MatchString(%v) {\nreturn errors.New(ctx.Lang().Regexp(%q, %q))\n}\n",
+				g.regexpVar(m.regexp), target, m.desc, m.regexp)
+		}
+	}
+	g.enumCheck(b, target, m)
+}
+
+func (g *generator) lenChecks(b *strings.Builder, length string, m fieldMeta) {
+	if m.max != nil {
+		g.use("errors", "errors")
+		fmt.Fprintf(b, "if uint64(%v) > %v {\nreturn errors.New(ctx.Lang().Max(%q, %v))\n}\n", length, *m.max, m.desc, *m.max)
+	}
+	if m.min > 0 {
+		g.use("errors", "errors")
+		fmt.Fprintf(b, "if uint64(%v) < %v {\nreturn errors.New(ctx.Lang().Min(%q, %v))\n}\n", length, m.min, m.desc, m.min)
+	}
+}
+
+// numberChecks see handlerServer.validFloat64
+func (g *generator) numberChecks(b *strings.Builder, target string, m fieldMeta) {
+	if m.lt == nil && m.lte == nil && m.gt == nil && m.gte == nil && m.enum == nil {
+		return
+	}
+	g.use("errors", "errors")
+	b.WriteString("{\n")
+	fmt.Fprintf(b, "f := float64(%v)\n", target)
+	if m.lt != nil {
+		fmt.Fprintf(b, "if f >= %v {\nreturn errors.New(ctx.Lang().Lt(%q, %v))\n}\n", float(*m.lt), m.desc, float(*m.lt))
+	}
+	if m.lte != nil {
+		fmt.Fprintf(b, "if f > %v {\nreturn errors.New(ctx.Lang().Lte(%q, %v))\n}\n", float(*m.lte), m.desc, float(*m.lte))
+	}
+	if m.gt != nil {
+		fmt.Fprintf(b, "if f <= %v {\nreturn errors.New(ctx.Lang().Gt(%q, %v))\n}\n", float(*m.gt), m.desc, float(*m.gt))
+	}
+	if m.gte != nil {
+		fmt.Fprintf(b, "if f < %v {\nreturn errors.New(ctx.Lang().Gte(%q, %v))\n}\n", float(*m.gte), m.desc, float(*m.gte))
+	}
+	g.enumCheck(b, "f", m)
+	b.WriteString("}\n")
+}
+
+func (g *generator) enumCheck(b *strings.Builder, target string, m fieldMeta) {
+	if m.enum == nil {
+		return
+	}
+	g.use("errors", "errors")
+	conds := make([]string, len(m.enum))
+	for k, v := range m.enum {
+		conds[k] = fmt.Sprintf("%v == %v", target, v)
+	}
+	fmt.Fprintf(b, "if !(%v) {\nreturn errors.New(ctx.Lang().Enum(%q, []any{%v}))\n}\n", strings.Join(conds, " || "),
+		m.desc, strings.Join(m.enum, ", "))
+}
+
+func (g *generator) regexpVar(expr string) string {
+	if name, ok := g.regexpNames[expr]; ok {
+		return name
+	}
+	if name, ok := g.cur.regexpNames[expr]; ok {
+		return name
+	}
+	g.use("regexp", "regexp")
+	name := fmt.Sprintf("goapiRegexp%v", len(g.regexpNames)+len(g.cur.regexpNames))
+	g.cur.regexpNames[expr] = name
+	g.cur.regexps = append(g.cur.regexps, fmt.Sprintf("%v = regexp.MustCompile(%q)", name, expr))
+	return name
+}
+
+// parseMeta parses the validation of the tags, see handler.handleMetaByField
+func (g *generator) parseMeta(tag reflect.StructTag, kind, fallback string, isParam bool) (m fieldMeta, err error) {
+	isNumber := isIntKind(kind) || isUintKind(kind) || kind == "float32" || kind == "float64"
+	isNormal := isNumber || kind == "string" || kind == "bool"
+	if tag.Get("multiple") != "" && isNumber {
+		err = fmt.Errorf("the tag 'multiple' is not supported")
+		return
+	}
+	if tag.Get("unique") != "" && kind == "slice" {
+		err = fmt.Errorf("the tag 'unique' is not supported")
+		return
+	}
+	m.desc = fallback
+	if desc := tag.Get("desc"); desc != "" {
+		if strings.Contains(desc, "{{") {
+			err = fmt.Errorf("the mapping tag 'desc' is not supported")
+			return
+		}
+		m.desc = desc
+	}
+	if name := tag.Get("name"); name != "" {
+		m.desc = name
+	}
+	if val := tag.Get("regexp"); val != "" && kind == "string" {
+		m.regexp = val
+	}
+	if val := tag.Get("enum"); val != "" && isNormal {
+		for _, item := range strings.Split(val, ",") {
+			item = strings.TrimSpace(item)
+			switch {
+			case isNumber:
+				var f float64
+				if f, err = strconv.ParseFloat(item, 64); err != nil {
+					return
+				}
+				m.enum = append(m.enum, fmt.Sprintf("float64(%v)", float(f)))
+			case kind == "bool":
+				var v bool
+				if v, err = strconv.ParseBool(item); err != nil {
+					return
+				}
+				m.enum = append(m.enum, strconv.FormatBool(v))
+			default:
+				m.enum = append(m.enum, strconv.Quote(item))
+			}
+		}
+	}
+	if isNumber {
+		for name, dst := range map[string]**float64{"lt": &m.lt, "lte": &m.lte, "gt": &m.gt, "gte": &m.gte} {
+			if val := tag.Get(name); val != "" {
+				var f float64
+				if f, err = strconv.ParseFloat(val, 64); err != nil {
+					return
+				}
+				*dst = &f
+			}
+		}
+	}
+	if kind == "string" || kind == "slice" {
+		if val := tag.Get("max"); val != "" {
+			var v uint64
+			if v, err = strconv.ParseUint(val, 10, 64); err != nil {
+				return
+			}
+			m.max = &v
+		}
+		if val := tag.Get("min"); val != "" {
+			if m.min, err = strconv.ParseUint(val, 10, 64); err != nil {
+				return
+			}
+		}
+	}
+	if val := tag.Get("default"); val != "" {
+		if err = m.parseDefault(val, kind, isParam); err != nil {
+			return
+		}
+	}
+	applyKindBounds(kind, &m)
+	return
+}
+
+// parseDefault the default of the parameter is the string, and the default of the body is set if it is not zero,
+// see defaultSet
+func (m *fieldMeta) parseDefault(val, kind string, isParam bool) error {
+	if isParam {
+		m.def, m.hasDef = val, true
+		return nil
+	}
+	switch {
+	case kind == "string":
+		m.def, m.hasDef = strconv.Quote(val), true
+	case kind == "bool":
+		v, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		m.def, m.hasDef = "true", v
+	case isIntKind(kind) || isUintKind(kind) || kind == "float32" || kind == "float64":
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return err
+		}
+		if f != math.Trunc(f) && kind != "float32" && kind != "float64" {
+			return fmt.Errorf("the default %v of %v is not supported", val, kind)
+		}
+		m.def, m.hasDef = float(f), f != 0
+	default:
+		m.hasDef = true
+	}
+	return nil
+}
+
+// applyKindBounds see handler.handleMetaByType
+func applyKindBounds(kind string, m *fieldMeta) {
+	minNum, maxNum := float64(0), float64(-1)
+	switch kind {
+	case "int8":
+		minNum, maxNum = math.MinInt8, math.MaxInt8
+	case "int16":
+		minNum, maxNum = math.MinInt16, math.MaxInt16
+	case "int32", "rune":
+		minNum, maxNum = math.MinInt32, math.MaxInt32
+	case "int64":
+		minNum, maxNum = math.MinInt64, math.MaxInt64
+	case "uint8", "byte":
+		maxNum = math.MaxUint8
+	case "uint16":
+		maxNum = math.MaxUint16
+	case "uint32":
+		maxNum = math.MaxUint32
+	case "uint64":
+		maxNum = math.MaxUint64
+	default:
+		return
+	}
+	if m.lte == nil || *m.lte > maxNum {
+		m.lte = &maxNum
+	}
+	if m.gte == nil || *m.gte < minNum {
+		m.gte = &minNum
+	}
+}
+
+// parseType parses the type of the field in the body
+func (g *generator) parseType(expr ast.Expr, allowSlice bool) (t *typeInfo, err error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if _, ok := g.structs[e.Name]; ok {
+			return &typeInfo{kind: "struct", name: e.Name}, nil
+		}
+		if _, ok := g.types[e.Name]; !ok && scalarKinds[e.Name] {
+			return &typeInfo{kind: e.Name}, nil
+		}
+	case *ast.StarExpr:
+		if t, err = g.parseType(e.X, false); err != nil {
+			return
+		}
+		if !t.ptr && t.kind != "slice" {
+			t.ptr = true
+			return
+		}
+	case *ast.ArrayType:
+		if e.Len == nil && allowSlice {
+			t = &typeInfo{kind: "slice"}
+			if t.elem, err = g.parseType(e.Elt, false); err != nil {
+				return
+			}
+			return
+		}
+	}
+	return nil, fmt.Errorf("the type %v is not supported", exprString(expr))
+}
+
+// scalarKind returns the builtin kind of the parameter
+func (g *generator) scalarKind(expr ast.Expr) (kind string, ptr bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr, ptr = star.X, true
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok || !scalarKinds[ident.Name] {
+		return "", false
+	}
+	if _, ok = g.types[ident.Name]; ok {
+		return "", false
+	}
+	return ident.Name, ptr
+}
+
+// isComparable returns whether the structure can be compared with its zero value
+func (g *generator) isComparable(name string, visiting map[string]bool) bool {
+	if visiting[name] {
+		return true
+	}
+	visiting[name] = true
+	st := g.structs[name]
+	if st == nil {
+		return false
+	}
+	for _, field := range st.Fields.List {
+		expr := field.Type
+		if _, ok := expr.(*ast.StarExpr); ok {
+			continue
+		}
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		if _, ok = g.structs[ident.Name]; ok {
+			if !g.isComparable(ident.Name, visiting) {
+				return false
+			}
+			continue
+		}
+		if _, ok = g.types[ident.Name]; ok || !scalarKinds[ident.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *generator) metaMethod(name string) string {
+	for _, method := range metaMethods {
+		if _, ok := g.methods[name][method]; ok {
+			return method
+		}
+	}
+	return ""
+}
+
+func (g *generator) source() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by goapi-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %v\n\n", g.pkgName)
+	if len(g.imports) > 0 {
+		names := make([]string, 0, len(g.imports))
+		for name := range g.imports {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return g.imports[names[i]] < g.imports[names[j]] ||
+				(g.imports[names[i]] == g.imports[names[j]] && names[i] < names[j])
+		})
+		b.WriteString("import (\n")
+		// the standard packages are in front
+		for _, std := range []bool{true, false} {
+			for _, name := range names {
+				path := g.imports[name]
+				if isStd(path) != std {
+					continue
+				}
+				if name == packageName(path) {
+					fmt.Fprintf(&b, "%q\n", path)
+				} else {
+					fmt.Fprintf(&b, "%v %q\n", name, path)
+				}
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(")\n\n")
+	}
+	if len(g.regexps) > 0 {
+		fmt.Fprintf(&b, "var (\n%v\n)\n\n", strings.Join(g.regexps, "\n"))
+	}
+	if len(g.registers) > 0 {
+		b.WriteString("func init() {\n")
+		for _, item := range g.registers {
+			fmt.Fprintf(&b, "goapi.RegisterBinder(%q, %v)\n", item.tagHash, item.name)
+		}
+		b.WriteString("}\n\n")
+	}
+	for _, code := range g.binders {
+		b.WriteString(code + "\n")
+	}
+	for _, code := range g.validators {
+		b.WriteString(code + "\n")
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format the generated code: %v", err)
+	}
+	return src, nil
+}
+
+func isStd(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+// packageName returns the default name of the imported package, the major version suffix is skipped
+func packageName(path string) string {
+	list := strings.Split(path, "/")
+	name := list[len(list)-1]
+	if len(list) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = list[len(list)-2]
+	}
+	return name
+}
+
+// goapiName returns the name of goapi imported by the file, empty if it is not imported
+func goapiName(f *ast.File) string {
+	if f == nil {
+		return ""
+	}
+	for _, imp := range f.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path != goapiPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return "goapi"
+	}
+	return ""
+}
+
+func recvName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func isSel(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == pkg
+}
+
+func isStarSel(expr ast.Expr, pkg, name string) bool {
+	star, ok := expr.(*ast.StarExpr)
+	return ok && isSel(star.X, pkg, name)
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, _ := strconv.Unquote(field.Tag.Value)
+	return reflect.StructTag(tag)
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+func isIntKind(kind string) bool {
+	switch kind {
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		return true
+	}
+	return false
+}
+
+func isUintKind(kind string) bool {
+	switch kind {
+	case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+		return true
+	}
+	return false
+}
+
+func zeroValue(kind string) string {
+	switch kind {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	}
+	return "0"
+}
+
+// float returns the literal of the float64
+func float(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateExample(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	g := newGenerator()
+	if err := g.parseDir(dir, "goapi_binder_gen.go"); err != nil {
+		t.Fatal(err)
+	}
+	src, err := g.generate([]string{"ListUsers"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "goapi_binder_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Errorf("the generated code of %v is out of date, run 'go generate' in it", dir)
+	}
+	if len(g.skips) != 1 || !strings.Contains(g.skips[0], "UserRouter.Admin") {
+		t.Errorf("skips: got %v want UserRouter.Admin", g.skips)
+	}
+	if _, err = g.generate([]string{"Missing"}); err == nil {
+		t.Errorf("the missing type should be an error")
+	}
+}
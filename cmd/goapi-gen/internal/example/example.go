@@ -0,0 +1,89 @@
+// Package example is the example of goapi-gen, the generated binders are tested with the reflection
+package example
+
+import (
+	"strconv"
+
+	"github.com/goodluckxu-go/goapi/v2"
+)
+
+//go:generate go run github.com/goodluckxu-go/goapi/v2/cmd/goapi-gen -types ListUsers
+
+type UserRouter struct{}
+
+type GetUser struct {
+	router goapi.Router `paths:"/users/{id}" methods:"GET"`
+	ID     int          `path:"id" gte:"1" lt:"1000"`
+	Lang   string       `query:"lang,omitempty" enum:"en,zh" default:"en"`
+	Limit  *int8        `query:"limit,omitempty"`
+	Token  string       `header:"X-Token" regexp:"^[a-z]+$" max:"8" desc:"token"`
+	Theme  string       `cookie:"theme,omitempty" min:"2"`
+	Ctx    *goapi.Context
+}
+
+func (u *UserRouter) Get(in GetUser) string {
+	limit := "nil"
+	if in.Limit != nil {
+		limit = strconv.Itoa(int(*in.Limit))
+	}
+	return in.Lang + "-" + in.Token + "-" + in.Theme + "-" + limit + "-" + in.Ctx.Request.URL.Path
+}
+
+type User struct {
+	Name    string   `json:"name" max:"5"`
+	Age     uint8    `json:"age,omitempty" gt:"0"`
+	Email   *string  `json:"email,omitempty" regexp:"@"`
+	Role    string   `json:"role,omitempty" default:"guest" enum:"guest,admin"`
+	Tags    []string `json:"tags,omitempty" max:"2"`
+	Address *Address `json:"address,omitempty"`
+	Friends []*User  `json:"friends,omitempty"`
+}
+
+type Address struct {
+	City string `json:"city" name:"the city"`
+	Zip  int    `json:"zip,omitempty" gte:"10000" lte:"99999"`
+}
+
+func (u *UserRouter) Create(input struct {
+	router goapi.Router `paths:"/users" methods:"POST"`
+	Body   *User        `body:"json"`
+}) *User {
+	return input.Body
+}
+
+func (u *UserRouter) Login(ctx *goapi.Context, input struct {
+	router   goapi.Router `paths:"/login" methods:"POST"`
+	Username string       `form:"username" max:"10"`
+	Remember bool         `form:"remember,omitempty"`
+}) string {
+	if input.Remember {
+		return input.Username + "-remember"
+	}
+	return input.Username
+}
+
+// Admin It is bound by the reflection, the security is not generated
+func (u *UserRouter) Admin(input struct {
+	router goapi.Router `paths:"/admin" methods:"GET"`
+	Auth   *AdminAuth
+}) string {
+	return "admin"
+}
+
+type AdminAuth struct{}
+
+func (a *AdminAuth) ApiKey() error {
+	return nil
+}
+
+// ListUsers It is the input of goapi.Get, generated by '-types'
+type ListUsers struct {
+	Page int `query:"page,omitempty" gte:"1"`
+}
+
+func Register(api *goapi.API) {
+	api.IncludeRouter(&UserRouter{}, "", true)
+	goapi.Get(api, "/list", func(ctx *goapi.Context, in ListUsers) (int, error) {
+		return in.Page, nil
+	})
+}
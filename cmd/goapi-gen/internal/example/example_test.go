@@ -0,0 +1,156 @@
+package example
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goodluckxu-go/goapi/v2"
+)
+
+// refRouter It is the same as UserRouter with the other paths, which is bound by the reflection
+type refRouter struct{}
+
+type refGetUser struct {
+	router goapi.Router `paths:"/ref/users/{id}" methods:"GET"`
+	ID     int          `path:"id" gte:"1" lt:"1000"`
+	Lang   string       `query:"lang,omitempty" enum:"en,zh" default:"en"`
+	Limit  *int8        `query:"limit,omitempty"`
+	Token  string       `header:"X-Token" regexp:"^[a-z]+$" max:"8" desc:"token"`
+	Theme  string       `cookie:"theme,omitempty" min:"2"`
+	Ctx    *goapi.Context
+}
+
+func (r *refRouter) Get(in refGetUser) string {
+	limit := "nil"
+	if in.Limit != nil {
+		limit = strconv.Itoa(int(*in.Limit))
+	}
+	return in.Lang + "-" + in.Token + "-" + in.Theme + "-" + limit + "-" + strings.TrimPrefix(in.Ctx.Request.URL.Path, "/ref")
+}
+
+func (r *refRouter) Create(input struct {
+	router goapi.Router `paths:"/ref/users" methods:"POST"`
+	Body   *User        `body:"json"`
+}) *User {
+	return input.Body
+}
+
+func (r *refRouter) Login(ctx *goapi.Context, input struct {
+	router   goapi.Router `paths:"/ref/login" methods:"POST"`
+	Username string       `form:"username" max:"10"`
+	Remember bool         `form:"remember,omitempty"`
+}) string {
+	if input.Remember {
+		return input.Username + "-remember"
+	}
+	return input.Username
+}
+
+type refListUsers struct {
+	Page int `query:"page,omitempty" gte:"1"`
+}
+
+// warnLogger records the warnings, the binders out of date are warned when the API is built
+type warnLogger struct {
+	warns []string
+}
+
+func (w *warnLogger) Debug(format string, a ...any) {}
+func (w *warnLogger) Info(format string, a ...any)  {}
+func (w *warnLogger) Warn(format string, a ...any) {
+	w.warns = append(w.warns, fmt.Sprintf(format, a...))
+}
+func (w *warnLogger) Error(format string, a ...any)                {}
+func (w *warnLogger) Fatal(format string, a ...any)                {}
+func (w *warnLogger) WithFields(keysAndValues ...any) goapi.Logger { return w }
+
+func TestGeneratedBinders(t *testing.T) {
+	api := goapi.New(true)
+	log := &warnLogger{}
+	api.SetLogger(log)
+	Register(api)
+	api.IncludeRouter(&refRouter{}, "", true)
+	goapi.Get(api, "/ref/list", func(ctx *goapi.Context, in refListUsers) (int, error) {
+		return in.Page, nil
+	})
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log.warns) > 0 {
+		t.Errorf("the tag hashes of the binders should be the same as goapi, got %q", log.warns)
+	}
+	form := url.Values{"username": {"tom"}, "remember": {"true"}}.Encode()
+	tests := []struct {
+		method      string
+		path        string
+		contentType string
+		token       string
+		cookie      string
+		body        string
+	}{
+		{method: http.MethodGet, path: "/users/7?limit=3", token: "abc", cookie: "dark"},
+		{method: http.MethodGet, path: "/users/7?lang=zh", token: "abc"},
+		{method: http.MethodGet, path: "/users/7?lang=fr", token: "abc"},
+		{method: http.MethodGet, path: "/users/7?limit=200", token: "abc"},
+		{method: http.MethodGet, path: "/users/7?limit=0", token: "abc"},
+		{method: http.MethodGet, path: "/users/7?limit=x", token: "abc"},
+		{method: http.MethodGet, path: "/users/0", token: "abc"},
+		{method: http.MethodGet, path: "/users/1000", token: "abc"},
+		{method: http.MethodGet, path: "/users/7"},
+		{method: http.MethodGet, path: "/users/7", token: "ABC"},
+		{method: http.MethodGet, path: "/users/7", token: "abcdefghi"},
+		{method: http.MethodGet, path: "/users/7", token: "abc", cookie: "d"},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","age":3,"tags":["a"],"address":{"city":"x"},"friends":[{"name":"ann"}]}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json; charset=utf-8", body: `{"name":"tom","email":"a@b"}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tommy"}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `null`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","age":300}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","email":"ab"}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","role":"root"}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","tags":["a","b","c"]}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","tags":[""]}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","address":{"zip":1}}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","address":{"city":"x","zip":1}}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","friends":[null]}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":"tom","friends":[{"name":"tommy"}]}`},
+		{method: http.MethodPost, path: "/users", contentType: "application/json", body: `{"name":`},
+		{method: http.MethodPost, path: "/users", contentType: "text/plain", body: `{}`},
+		{method: http.MethodPost, path: "/login", contentType: "application/x-www-form-urlencoded", body: form},
+		{method: http.MethodPost, path: "/login", contentType: "application/x-www-form-urlencoded", body: "username=tom"},
+		{method: http.MethodPost, path: "/login", contentType: "application/x-www-form-urlencoded", body: "remember=yes"},
+		{method: http.MethodPost, path: "/login", contentType: "application/x-www-form-urlencoded", body: "username=tom&remember=x"},
+		{method: http.MethodGet, path: "/list?page=2"},
+		{method: http.MethodGet, path: "/list?page=-1"},
+		{method: http.MethodGet, path: "/list"},
+	}
+	serve := func(method, path, contentType, token, cookie, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		if token != "" {
+			r.Header.Set("X-Token", token)
+		}
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "theme", Value: cookie})
+		}
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	for _, tt := range tests {
+		got := serve(tt.method, tt.path, tt.contentType, tt.token, tt.cookie, tt.body)
+		want := serve(tt.method, "/ref"+tt.path, tt.contentType, tt.token, tt.cookie, tt.body)
+		if got.Code != want.Code || got.Body.String() != want.Body.String() {
+			t.Errorf("%v %v %v: got %v %q want %v %q", tt.method, tt.path, tt.body, got.Code, got.Body.String(),
+				want.Code, want.Body.String())
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Code generated by goapi-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goodluckxu-go/goapi/v2"
+)
+
+var (
+	goapiRegexp0 = regexp.MustCompile("^[a-z]+$")
+	goapiRegexp1 = regexp.MustCompile("@")
+)
+
+func init() {
+	goapi.RegisterBinder("8c4101de23a91e65", goapiBindGetUser)
+	goapi.RegisterBinder("66b12e68fa94e349", goapiBindUserRouterCreate)
+	goapi.RegisterBinder("b4c8a8e81f17c03b", goapiBindUserRouterLogin)
+	goapi.RegisterBinder("a4d0a14706b522a0", goapiBindListUsers)
+}
+
+func goapiBindGetUser(ctx *goapi.Context, in *GetUser) error {
+	// ID
+	if val, ok := ctx.Params.Get("id"); ok {
+		if val == "" {
+			return errors.New(ctx.Lang().Required("id"))
+		}
+		v, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		if v == 0 {
+			return errors.New(ctx.Lang().Required("id"))
+		}
+		{
+			f := float64(v)
+			if f >= 1000 {
+				return errors.New(ctx.Lang().Lt("id", 1000))
+			}
+			if f < 1 {
+				return errors.New(ctx.Lang().Gte("id", 1))
+			}
+		}
+		in.ID = int(v)
+	}
+	// Lang
+	{
+		val := ""
+		if values := ctx.Query()["lang"]; len(values) > 0 {
+			val = values[0]
+		}
+		if val == "" {
+			val = "en"
+		}
+		if !(val == "en" || val == "zh") {
+			return errors.New(ctx.Lang().Enum("lang", []any{"en", "zh"}))
+		}
+		in.Lang = val
+	}
+	// Limit
+	{
+		val := ""
+		if values := ctx.Query()["limit"]; len(values) > 0 {
+			val = values[0]
+		}
+		if val != "" {
+			if in.Limit == nil {
+				in.Limit = new(int8)
+			}
+			v, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return err
+			}
+			if v != 0 {
+				{
+					f := float64(v)
+					if f > 127 {
+						return errors.New(ctx.Lang().Lte("limit", 127))
+					}
+					if f < -128 {
+						return errors.New(ctx.Lang().Gte("limit", -128))
+					}
+				}
+				*in.Limit = int8(v)
+			}
+		}
+	}
+	// Token
+	{
+		val := ctx.Request.Header.Get("X-Token")
+		if val == "" {
+			return errors.New(ctx.Lang().Required("token"))
+		}
+		if uint64(len(val)) > 8 {
+			return errors.New(ctx.Lang().Max("token", 8))
+		}
+		if !goapiRegexp0.MatchString(val) {
+			return errors.New(ctx.Lang().Regexp("token", "^[a-z]+$"))
+		}
+		in.Token = val
+	}
+	// Theme
+	{
+		val := ""
+		if cookie, err := ctx.Request.Cookie("theme"); err == nil {
+			val = cookie.Value
+		}
+		if val != "" {
+			if uint64(len(val)) < 2 {
+				return errors.New(ctx.Lang().Min("theme", 2))
+			}
+			in.Theme = val
+		}
+	}
+	in.Ctx = ctx
+	return nil
+}
+
+func goapiBindUserRouterCreate(ctx *goapi.Context, in *struct {
+	router goapi.Router `paths:"/users" methods:"POST"`
+	Body   *User        `body:"json"`
+}) error {
+	// Body
+	{
+		mediaType, _, _ := strings.Cut(ctx.Request.Header.Get("Content-Type"), ";")
+		if goapi.MediaType(mediaType).MediaType() != goapi.JSON.MediaType() {
+			return goapi.NewHTTPError(http.StatusUnsupportedMediaType, http.StatusText(http.StatusUnsupportedMediaType))
+		}
+		if in.Body == nil {
+			in.Body = new(User)
+		}
+		if ctx.Request.Body != nil {
+			if err := goapi.JSON.Unmarshaler(ctx.Request.Body, reflect.ValueOf(in.Body)); err != nil {
+				return err
+			}
+		}
+		if err := goapiValidateUser(ctx, in.Body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func goapiBindUserRouterLogin(ctx *goapi.Context, in *struct {
+	router   goapi.Router `paths:"/login" methods:"POST"`
+	Username string       `form:"username" max:"10"`
+	Remember bool         `form:"remember,omitempty"`
+}) error {
+	if err := ctx.Request.ParseForm(); err != nil {
+		return err
+	}
+	// Username
+	{
+		val := ctx.Request.Form.Get("username")
+		if val == "" {
+			return errors.New(ctx.Lang().Required("username"))
+		}
+		if uint64(len(val)) > 10 {
+			return errors.New(ctx.Lang().Max("username", 10))
+		}
+		in.Username = val
+	}
+	// Remember
+	{
+		val := ctx.Request.Form.Get("remember")
+		if val != "" {
+			v, err := strconv.ParseBool(val)
+			if err != nil {
+				return err
+			}
+			in.Remember = v
+		}
+	}
+	return nil
+}
+
+func goapiBindListUsers(ctx *goapi.Context, in *ListUsers) error {
+	// Page
+	{
+		val := ""
+		if values := ctx.Query()["page"]; len(values) > 0 {
+			val = values[0]
+		}
+		if val != "" {
+			v, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return err
+			}
+			if v != 0 {
+				{
+					f := float64(v)
+					if f < 1 {
+						return errors.New(ctx.Lang().Gte("page", 1))
+					}
+				}
+				in.Page = int(v)
+			}
+		}
+	}
+	return nil
+}
+
+func goapiValidateAddress(ctx *goapi.Context, v *Address) error {
+	if v.City == "" {
+		return errors.New(ctx.Lang().Required("the city"))
+	}
+	if v.Zip != 0 {
+		{
+			f := float64(v.Zip)
+			if f > 99999 {
+				return errors.New(ctx.Lang().Lte("zip", 99999))
+			}
+			if f < 10000 {
+				return errors.New(ctx.Lang().Gte("zip", 10000))
+			}
+		}
+	}
+	return nil
+}
+
+func goapiValidateUser(ctx *goapi.Context, v *User) error {
+	if v.Name == "" {
+		return errors.New(ctx.Lang().Required("name"))
+	}
+	if uint64(len(v.Name)) > 5 {
+		return errors.New(ctx.Lang().Max("name", 5))
+	}
+	if v.Age != 0 {
+		{
+			f := float64(v.Age)
+			if f > 255 {
+				return errors.New(ctx.Lang().Lte("age", 255))
+			}
+			if f <= 0 {
+				return errors.New(ctx.Lang().Gt("age", 0))
+			}
+			if f < 0 {
+				return errors.New(ctx.Lang().Gte("age", 0))
+			}
+		}
+	}
+	if v.Email != nil {
+		if !goapiRegexp1.MatchString(*v.Email) {
+			return errors.New(ctx.Lang().Regexp("email", "@"))
+		}
+	}
+	if v.Role == "" {
+		v.Role = "guest"
+	}
+	if !(v.Role == "guest" || v.Role == "admin") {
+		return errors.New(ctx.Lang().Enum("role", []any{"guest", "admin"}))
+	}
+	if v.Tags != nil {
+		if uint64(len(v.Tags)) > 2 {
+			return errors.New(ctx.Lang().Max("tags", 2))
+		}
+		for i := range v.Tags {
+			if v.Tags[i] == "" {
+				return errors.New(ctx.Lang().Required("string"))
+			}
+		}
+	}
+	if v.Address != nil {
+		if err := goapiValidateAddress(ctx, v.Address); err != nil {
+			return err
+		}
+	}
+	if v.Friends != nil {
+		for i := range v.Friends {
+			if v.Friends[i] == nil {
+				return errors.New(ctx.Lang().Required("User"))
+			}
+			if err := goapiValidateUser(ctx, v.Friends[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
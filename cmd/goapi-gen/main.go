@@ -0,0 +1,51 @@
+// Command goapi-gen generates the binders of the router inputs, which bind and validate the requests
+// by the plain Go code instead of the reflection, see goapi.RegisterBinder.
+//
+// The inputs of the methods of the router structures in the package are generated, and the named
+// structures in '-types' are generated too, such as the inputs of goapi.Get.
+// The inputs which are not supported are skipped and still bound by the reflection, such as the
+// security, the file and the parameters of the custom types.
+//
+// usage:
+//
+//	//go:generate go run github.com/goodluckxu-go/goapi/v2/cmd/goapi-gen
+//	//go:generate go run github.com/goodluckxu-go/goapi/v2/cmd/goapi-gen -types CreateUser,GetUser
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "the directory of the package")
+	output := flag.String("output", "goapi_binder_gen.go", "the name of the generated file")
+	types := flag.String("types", "", "the named input structures to generate besides the routers, separated by ','")
+	flag.Parse()
+	var typeList []string
+	if *types != "" {
+		typeList = strings.Split(*types, ",")
+	}
+	g := newGenerator()
+	if err := g.parseDir(*dir, *output); err != nil {
+		fatal(err)
+	}
+	src, err := g.generate(typeList)
+	if err != nil {
+		fatal(err)
+	}
+	for _, skip := range g.skips {
+		fmt.Fprintf(os.Stderr, "goapi-gen: %v\n", skip)
+	}
+	if err = os.WriteFile(filepath.Join(*dir, *output), src, 0644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "goapi-gen: %v\n", err)
+	os.Exit(1)
+}
@@ -4,6 +4,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
 	"runtime/debug"
 	"strings"
 	"sync"
@@ -29,8 +30,11 @@ type Context struct {
 	RequestID     string
 	RouterSummary string
 	handleError   func(ctx *Context, err error)
+	server        *handlerServer
 	isRedirect    bool
 	langInfo      Lang
+	provided      map[*provider]reflect.Value // the values of the providers of ScopeRequest
+	cleanups      []func()                    // the cleanups of the providers, executed after the response
 	// prefix has 'x-'
 	Extensions Extensions
 }
@@ -55,6 +59,18 @@ func (c *Context) reset() {
 	c.RequestID = ""
 	c.isRedirect = false
 	c.Extensions = nil
+	for k := range c.provided {
+		delete(c.provided, k)
+	}
+	c.cleanups = c.cleanups[:0]
+}
+
+// runCleanups executes the cleanups of the providers in reverse order
+func (c *Context) runCleanups() {
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		c.cleanups[i]()
+		c.cleanups[i] = nil
+	}
 }
 
 func (c *Context) Deadline() (deadline time.Time, ok bool) {
@@ -114,6 +130,27 @@ func (c *Context) FullPath() string {
 	return c.fullPath
 }
 
+// Route returns the information of the matched router, it returns nil for not found routes
+func (c *Context) Route() *RouteInfo {
+	if c.path == nil || c.Request == nil {
+		return nil
+	}
+	route := newRouteInfo(c.path, c.Request.Method, c.fullPath, "")
+	if c.server != nil {
+		route.Addr = c.server.addr
+	}
+	return &route
+}
+
+// URLFor returns the path of the router with the 'name' tag, the params are key-value pairs, see API.URL
+func (c *Context) URLFor(name string, params ...string) (string, error) {
+	var path *pathInfo
+	if c.server != nil {
+		path = c.server.handle.namedPaths[name]
+	}
+	return buildURL(name, path, params)
+}
+
 // Next It is used in middleware, before Next is before interface request, and after Next is after interface request
 func (c *Context) Next() {
 	defer func() {
@@ -144,9 +181,11 @@ func (c *Context) Copy() *Context {
 		log:         c.log,
 		baseLog:     c.baseLog,
 		fullPath:    c.fullPath,
+		path:        c.path,
 		ChildPath:   c.ChildPath,
 		RequestID:   c.RequestID,
 		handleError: c.handleError,
+		server:      c.server,
 		langInfo:    c.langInfo,
 		Extensions:  c.Extensions,
 	}
@@ -204,25 +243,43 @@ func (c *Context) RemoteIP() string {
 }
 
 // ClientIP implements one best effort algorithm to return the real client IP.
-// It is it will then try to parse the headers defined in http.Header (defaulting to [X-Forwarded-For, X-Real-Ip]).
+// When the remote IP is a trusted proxy (see API.SetTrustedProxies, all the proxies are trusted by default),
+// it parses the headers defined in API.RemoteIPHeaders (defaulting to [X-Forwarded-For, X-Real-Ip],
+// 'Forwarded' of RFC 7239 is also supported), the IPs are walked from the right and the trusted proxies are skipped.
 // else the remote IP (coming from Request.RemoteAddr) is returned.
 func (c *Context) ClientIP() string {
 	remoteIP := net.ParseIP(c.RemoteIP())
 	if remoteIP == nil {
 		return ""
 	}
-	if xForwardedFor := c.Request.Header.Get("X-Forwarded-For"); xForwardedFor != "" {
-		xForwardedFor, _, _ = strings.Cut(xForwardedFor, ",")
-		ip := net.ParseIP(xForwardedFor)
-		if ip != nil {
-			return ip.String()
-		}
+	isTrusted := func(ip net.IP) bool {
+		return c.server == nil || c.server.handle.api.isTrustedProxy(ip)
+	}
+	if !isTrusted(remoteIP) {
+		return remoteIP.String()
+	}
+	headers := defaultRemoteIPHeaders
+	if c.server != nil && len(c.server.handle.api.RemoteIPHeaders) > 0 {
+		headers = c.server.handle.api.RemoteIPHeaders
 	}
-	if xRealIP := c.Request.Header.Get("X-Real-IP"); xRealIP != "" {
-		xRealIP, _, _ = strings.Cut(xRealIP, ",")
-		ip := net.ParseIP(xRealIP)
-		if ip != nil {
-			return ip.String()
+	for _, header := range headers {
+		var ips []string
+		if http.CanonicalHeaderKey(header) == "Forwarded" {
+			ips = parseForwardedFor(c.Request.Header.Values(header))
+		} else {
+			for _, value := range c.Request.Header.Values(header) {
+				ips = append(ips, strings.Split(value, ",")...)
+			}
+		}
+		// walk from the right, the first IP that is not trusted is the client IP
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(ips[i]))
+			if ip == nil {
+				break
+			}
+			if i == 0 || !isTrusted(ip) {
+				return ip.String()
+			}
 		}
 	}
 	return remoteIP.String()
@@ -254,6 +311,11 @@ func (c *Context) Redirect(status int, location string) {
 	}
 }
 
+// Lang It is the language of the request, which is used to translate the validation errors
+func (c *Context) Lang() Lang {
+	return c.langInfo
+}
+
 func (c *Context) lang() Lang {
 	return c.langInfo
 }
@@ -2,9 +2,13 @@ package goapi
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -21,6 +25,23 @@ func (n nopLogger) WithFields(keysAndValues ...any) Logger {
 	return n
 }
 
+// warnTestLogger records the warnings
+type warnTestLogger struct {
+	nopLogger
+	mux   sync.Mutex
+	warns []string
+}
+
+func (w *warnTestLogger) Warn(format string, a ...any) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.warns = append(w.warns, fmt.Sprintf(format, a...))
+}
+
+func (w *warnTestLogger) WithFields(keysAndValues ...any) Logger {
+	return w
+}
+
 // newTestContext builds a Context wired like production (recorder + request).
 func newTestContext(t *testing.T, req *http.Request) *Context {
 	t.Helper()
@@ -230,6 +251,108 @@ func TestContext_ClientIP(t *testing.T) {
 	})
 }
 
+func TestContext_ClientIPTrustedProxies(t *testing.T) {
+	api := New(false)
+	if err := api.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "skips trusted hops from the right",
+			remoteAddr: "10.0.0.1:8080",
+			headers:    map[string]string{"X-Forwarded-For": "1.1.1.1, 203.0.113.9, 192.168.1.1, 10.0.0.2"},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "ignores headers from an untrusted peer",
+			remoteAddr: "198.51.100.7:8080",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.9"},
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "returns the leftmost IP when all hops are trusted",
+			remoteAddr: "10.0.0.1:8080",
+			headers:    map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"},
+			want:       "10.0.0.3",
+		},
+		{
+			name:       "falls back to X-Real-IP when X-Forwarded-For is invalid",
+			remoteAddr: "10.0.0.1:8080",
+			headers:    map[string]string{"X-Forwarded-For": "unknown", "X-Real-IP": "203.0.113.10"},
+			want:       "203.0.113.10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			ctx := newTestContext(t, req)
+			ctx.server = &handlerServer{handle: &handler{api: api}}
+			if got := ctx.ClientIP(); got != tt.want {
+				t.Fatalf("ClientIP: want %v, got %q", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("parses the Forwarded header", func(t *testing.T) {
+		api.RemoteIPHeaders = []string{"Forwarded"}
+		defer func() {
+			api.RemoteIPHeaders = nil
+		}()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:8080"
+		req.Header.Set("Forwarded", `for="[2001:db8:cafe::17]:4711";proto=https, for=192.168.1.1;by=10.0.0.1`)
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		ctx := newTestContext(t, req)
+		ctx.server = &handlerServer{handle: &handler{api: api}}
+		if got := ctx.ClientIP(); got != "2001:db8:cafe::17" {
+			t.Fatalf("ClientIP: want 2001:db8:cafe::17, got %q", got)
+		}
+	})
+
+	t.Run("rejects invalid CIDRs", func(t *testing.T) {
+		if err := New(false).SetTrustedProxies([]string{"10.0.0.0/40"}); err == nil {
+			t.Fatal("SetTrustedProxies should reject an invalid CIDR")
+		}
+	})
+
+	t.Run("warns when the proxies are not set", func(t *testing.T) {
+		for _, set := range []bool{false, true} {
+			api := New(false)
+			log := &warnTestLogger{}
+			api.SetLogger(log)
+			if set {
+				_ = api.SetTrustedProxies(nil)
+			}
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			runErr := make(chan error, 1)
+			go func() {
+				runErr <- api.RunListener(ln)
+			}()
+			waitServing(t, ln.Addr().String())
+			_ = api.Shutdown(context.Background())
+			if err = <-runErr; err != nil {
+				t.Fatal(err)
+			}
+			warned := strings.Contains(strings.Join(log.warns, "\n"), "SetTrustedProxies")
+			if warned == set {
+				t.Fatalf("SetTrustedProxies called %v: got warning %v", set, log.warns)
+			}
+		}
+	})
+}
+
 func TestContext_Query(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/search?q=hello&tag=go", nil)
 	ctx := newTestContext(t, req)
@@ -23,6 +23,7 @@ func (InType) List() []InType {
 		inTypeHeader,
 		inTypeCookie,
 		inTypePath,
+		inTypeHost,
 		inTypeQuery,
 		inTypeForm,
 		inTypeFile,
@@ -31,7 +32,7 @@ func (InType) List() []InType {
 }
 
 func (i InType) IsSingle() bool {
-	if inArray(i, []InType{inTypePath, inTypeQuery, inTypeHeader, inTypeCookie, inTypeForm, inTypeFile}) {
+	if inArray(i, []InType{inTypePath, inTypeHost, inTypeQuery, inTypeHeader, inTypeCookie, inTypeForm, inTypeFile}) {
 		return true
 	}
 	return false
@@ -44,6 +45,7 @@ func (i InType) Tag() string {
 const (
 	// single value
 	inTypePath   InType = "path"
+	inTypeHost   InType = "host" // the wildcard of RouterGroup.Host
 	inTypeQuery  InType = "query"
 	inTypeHeader InType = "header"
 	inTypeCookie InType = "cookie"
@@ -57,7 +59,8 @@ const (
 	inTypeSecurityHTTPBasic     InType = "HTTPBasic"
 	inTypeSecurityApiKey        InType = "ApiKey"
 	// Other assignable parameters
-	inTypeCtx InType = "Ctx" //  goapi.Context
+	inTypeCtx    InType = "Ctx"    //  goapi.Context
+	inTypeDepend InType = "Depend" // the value of the provider registered by API.Provide
 )
 
 const returnMediaTypeField = "media_type"
@@ -220,11 +223,13 @@ const (
 	tagMax        = "max"        // VALIDATION. openapi's maxLength,maxItems,maxProperties
 	tagMin        = "min"        // VALIDATION. openapi's minLength,minItems,minProperties
 	tagUnique     = "unique"     // VALIDATION. openapi's uniqueItems
-	tagName       = "name"       // Alias during verification, if not present, use desc
+	tagName       = "name"       // Alias during verification, if not present, use desc. The name of 'goapi.Router', see API.URL
 	tagPaths      = "paths"
 	tagMethods    = "methods"
 	tagSummary    = "summary"
 	tagTags       = "tags"
+	tagMiddle     = "middlewares" // the names of the middlewares of 'goapi.Router', see API.RegisterMiddleware
+	tagSkip       = "skip"        // the names of the middlewares skipped by 'goapi.Router', see API.RegisterMiddleware
 )
 
 var (
@@ -236,6 +241,9 @@ var (
 
 const omitempty = "omitempty"
 
+// defaultRemoteIPHeaders are the headers used by '*Context.ClientIP' when API.RemoteIPHeaders is empty
+var defaultRemoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
 var typeContext = reflect.TypeOf(&Context{})
 
 // inTypeFile
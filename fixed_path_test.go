@@ -0,0 +1,76 @@
+package goapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fixedPathTestRouter struct{}
+
+func (r *fixedPathTestRouter) User(input struct {
+	router Router `paths:"/users/{id}" methods:"GET,POST"`
+	ID     string `path:"id"`
+}) string {
+	return input.ID
+}
+
+func TestCleanPath(t *testing.T) {
+	tests := map[string]string{
+		"":                  "/",
+		"users":             "/users",
+		"//users//1":        "/users/1",
+		"/users/../users/1": "/users/1",
+		"/users/./1/":       "/users/1/",
+		"/..":               "/",
+	}
+	for p, want := range tests {
+		if got := cleanPath(p); got != want {
+			t.Errorf("cleanPath(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
+
+func TestFixedPath(t *testing.T) {
+	newHandler := func(redirect, caseInsensitive bool) http.Handler {
+		api := New(true)
+		api.SetLogger(nil)
+		api.RedirectFixedPath = redirect
+		api.CaseInsensitive = caseInsensitive
+		api.IncludeRouter(&fixedPathTestRouter{}, "", false)
+		handler, err := api.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return handler
+	}
+	tests := []struct {
+		name            string
+		redirect        bool
+		caseInsensitive bool
+		method          string
+		path            string
+		code            int
+		location        string
+	}{
+		{name: "disabled", method: http.MethodGet, path: "//users/1", code: http.StatusNotFound},
+		{name: "clean", redirect: true, method: http.MethodGet, path: "//users/../users/1?a=1",
+			code: http.StatusMovedPermanently, location: "/users/1?a=1"},
+		{name: "clean post", redirect: true, method: http.MethodPost, path: "/users//1",
+			code: http.StatusPermanentRedirect, location: "/users/1"},
+		{name: "case sensitive", redirect: true, method: http.MethodGet, path: "/Users/1", code: http.StatusNotFound},
+		{name: "case insensitive", redirect: true, caseInsensitive: true, method: http.MethodGet, path: "//USERS/Tom",
+			code: http.StatusMovedPermanently, location: "/users/Tom"},
+		{name: "serve directly", caseInsensitive: true, method: http.MethodGet, path: "/USERS/Tom", code: http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		newHandler(tt.redirect, tt.caseInsensitive).ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("%v: got %v %q want %v %q", tt.name, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+		if tt.code == http.StatusOK && w.Body.String() != `"Tom"` {
+			t.Errorf("%v: got body %q", tt.name, w.Body.String())
+		}
+	}
+}
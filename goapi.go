@@ -1,10 +1,16 @@
 package goapi
 
 import (
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goodluckxu-go/goapi/v2/openapi"
 )
 
 // New It is a newly created API function
@@ -17,31 +23,70 @@ func New(isDocs bool, docsPath ...string) *API {
 		log:                  &DefaultLogger{},
 		addr:                 ":8080",
 		structTagVariableMap: map[string]any{},
+		servers:              map[*http.Server]struct{}{},
+		shutdownDone:         make(chan struct{}),
 	}
-	api.RouterChild = &RouterChild{}
+	api.RouterChild = &RouterChild{RouterGroup: RouterGroup{providers: &providers{}}}
 	api.init()
+	api.Provide(api.provideBackgroundTasks)
 	api.isDocs = isDocs
 	api.docsPath = dPath
 	return api
 }
 
-// Default returns an API instance with the LoggerMiddleware middleware already attached.
+// Default returns an API instance with the LoggerMiddleware middleware already attached,
+// it is registered as 'logger' and can be skipped by the 'skip' tag.
 func Default(isDocs bool, docsPath ...string) *API {
 	api := New(isDocs, docsPath...)
-	api.defaultMiddlewares = append(api.defaultMiddlewares, LoggerMiddleware())
-	api.AddMiddleware(api.defaultMiddlewares...)
+	api.RegisterMiddleware("logger", LoggerMiddleware())
+	api.UseMiddleware("logger")
 	return api
 }
 
 type API struct {
 	IRouters
-	defaultMiddlewares   []HandleFunc
 	langList             []Lang
 	log                  Logger
 	addr                 string
 	structTagVariableMap map[string]any
+	structTagVariableErr error
+	serverConfig         ServerConfig
+	binds                []bindInfo
+	listenBind           func(addr string) (net.Listener, error) // listens on the addresses of Bind, default net.Listen
+	serverMux            sync.Mutex
+	servers              map[*http.Server]struct{}
+	inShutdown           bool
+	shutdownDone         chan struct{}
+	startupHooks         []LifespanFunc
+	shutdownHooks        []LifespanFunc
+	lifespanMux          sync.Mutex
+	isStarted            bool
 	GenerateRequestID    bool // '*Context' can obtain the value of RequestID
 	UseXRequestIDHeader  bool // when GenerateRequestID is true, use the 'X-Request-ID' request/response header
+
+	LifespanTimeout time.Duration // the deadline of OnStartup and OnShutdown hooks, default 30s
+	// the headers used by '*Context.ClientIP' in order, default 'X-Forwarded-For' and 'X-Real-IP'.
+	// They can be spoofed by the clients unless SetTrustedProxies is called, all the proxies are trusted by default,
+	// and a warning is written when the server starts
+	RemoteIPHeaders []string
+
+	BackgroundWorkers int           // the number of the workers executing BackgroundTasks, default 16
+	BackgroundTimeout time.Duration // the deadline of the BackgroundTasks when the API shuts down, before the OnShutdown hooks, default 30s
+	background        backgroundPool
+
+	trustedProxies      []*net.IPNet
+	isSetTrustedProxies bool
+
+	routeMux   sync.RWMutex
+	namedPaths map[string]*pathInfo // the routers with the 'name' tag of the last Build
+	routes     []RouteInfo          // the routers of the last Build
+
+	middlewareMap  map[string]HandleFunc // the middlewares of RegisterMiddleware
+	middlewareErrs []error               // the errors of RegisterMiddleware, returned by Build
+
+	updateMux sync.Mutex              // serializes Update and Reload
+	swapMux   sync.Mutex              // protects swaps
+	swaps     map[string]*handlerSwap // the handlers of the addresses, see Reload
 }
 
 // SetLang It is to set the validation language function
@@ -64,6 +109,28 @@ func (a *API) SetLogger(log Logger) {
 	a.log = log
 }
 
+// SetTrustedProxies It is to set the IPs or CIDRs of the proxies trusted by '*Context.ClientIP'
+// The RemoteIPHeaders are only used when the remote IP is trusted, and the header IPs are walked from the right,
+// the first IP that is not trusted is the client IP. All the proxies are trusted by default for compatibility,
+// which is unsafe when the server is reachable without a proxy, a warning is written when the server starts.
+// Passing nil means no proxy is trusted and the remote IP is always used
+func (a *API) SetTrustedProxies(trustedProxies []string) (err error) {
+	cidrs, err := parseCIDRs(trustedProxies)
+	if err != nil {
+		return
+	}
+	a.trustedProxies = cidrs
+	a.isSetTrustedProxies = true
+	return
+}
+
+func (a *API) isTrustedProxy(ip net.IP) bool {
+	if !a.isSetTrustedProxies {
+		return true
+	}
+	return ipInCIDRs(ip, a.trustedProxies)
+}
+
 // Logger It is a method of obtaining logs
 func (a *API) Logger() Logger {
 	return a.log
@@ -71,6 +138,7 @@ func (a *API) Logger() Logger {
 
 // SetStructTagVariableMapping It is set struct tag variable mapping
 // Only supports the replacement of tags 'summary' and 'desc'
+// An invalid key is returned by Build
 // example:
 //
 //	mapping:
@@ -86,54 +154,126 @@ func (a *API) SetStructTagVariableMapping(m map[string]string) {
 		n := len(k)
 		for i := 0; i < n; i++ {
 			if k[i] == '{' || k[i] == '}' {
-				log.Fatal("the struct tag variable mapping key cannot be within '{', '}'")
+				a.structTagVariableErr = fmt.Errorf("the struct tag variable mapping key cannot be within '{', '}', key: %v", k)
+				return
 			}
 		}
 		a.structTagVariableMap[k] = v
 	}
 }
 
-// DebugPprof Open the system's built-in pprof
-func (a *API) DebugPprof() {
-	a.IncludeRouter(debugPprof, "/debug", false)
-}
-
 // Run attaches the router to a http.Server and starts listening and serving HTTP requests.
-// It is a shortcut for http.ListenAndServe(addr, router)
-// Note: this method will block the calling goroutine indefinitely unless an error happens.
+// The http.Server is configured by Server, see ServerConfig
+// The configuration errors of the routers are returned as *BuildError before listening.
+// Note: this method will block the calling goroutine indefinitely unless an error happens or Shutdown is called.
 func (a *API) Run(addr ...string) (err error) {
 	if len(addr) > 0 {
 		a.addr = addr[0]
 	}
-	httpHandler := a.Handler()
-	a.writeLogInfo(a.log, "GoAPI running on http://%v (Press CTRL+C to quit)", a.addr)
-	return http.ListenAndServe(a.addr, httpHandler)
+	return a.run("http", a.addr, func() (net.Listener, error) {
+		return net.Listen("tcp", a.addr)
+	}, func(server *http.Server, ln net.Listener) error {
+		return server.Serve(ln)
+	}, nil)
 }
 
 // RunTLS attaches the router to a http.Server and starts listening and serving HTTPS (secure) requests.
-// It is a shortcut for http.ListenAndServeTLS(addr, certFile, keyFile, router)
-// Note: this method will block the calling goroutine indefinitely unless an error happens.
+// The http.Server is configured by Server, see ServerConfig
+// certFile and keyFile can be empty when ServerConfig.TLSConfig has Certificates or GetCertificate
+// Note: this method will block the calling goroutine indefinitely unless an error happens or Shutdown is called.
 func (a *API) RunTLS(addr, certFile, keyFile string) (err error) {
 	a.addr = addr
-	httpHandler := a.Handler()
-	a.writeLogInfo(a.log, "GoAPI running on https://%v (Press CTRL+C to quit)", a.addr)
-	return http.ListenAndServeTLS(a.addr, certFile, keyFile, httpHandler)
+	return a.run("https", a.addr, func() (net.Listener, error) {
+		return net.Listen("tcp", a.addr)
+	}, func(server *http.Server, ln net.Listener) error {
+		return server.ServeTLS(ln, certFile, keyFile)
+	}, nil)
 }
 
-// Handler Return to http.Handler interface
+// Handler Return to http.Handler interface, the routers bound by Bind are not included
+// The process exits when there are configuration errors, use Build to handle them
 func (a *API) Handler() http.Handler {
+	handlers, err := a.handlerServers()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return handlers[""]
+}
+
+// Build It is to analyze all the routers and return the http.Handler, the routers bound by Bind are not included
+// All the configuration errors are collected with their pos, and returned together as *BuildError.
+// The handlers returned by every Build, Handler and Run are the same, they serve the routers of the last successful
+// Build, Reload or Update, so the handler of an earlier Build also serves the routers changed later
+func (a *API) Build() (http.Handler, error) {
+	a.updateMux.Lock()
+	defer a.updateMux.Unlock()
+	handlers, err := a.buildServers()
+	if err != nil {
+		return nil, err
+	}
+	return handlers[""], nil
+}
+
+// handlerServers builds the handlers of all the addresses, the configuration errors are returned as *BuildError
+func (a *API) handlerServers() (map[string]http.Handler, error) {
 	pid := ColorDebug(strconv.Itoa(os.Getpid()))
 	a.writeLogInfo(a.log, "Started server process [%v]", pid)
+	a.updateMux.Lock()
+	defer a.updateMux.Unlock()
+	return a.buildServers()
+}
+
+// buildServers returns the handlers by the address of Bind, the handler of the empty address serves the other routers
+func (a *API) buildServers() (handlers map[string]http.Handler, err error) {
 	handle := newHandler(a)
-	handle.Handle()
-	serverHandle := newHandlerServer(handle, a.log)
-	if a.isDocs {
+	errs := appendBuildError(nil, handle.Handle())
+	addrMap, err := a.bindAddrMap()
+	errs = appendBuildError(errs, err)
+	docsAddrMap, err := bindDocsAddrMap(handle.paths, addrMap)
+	errs = appendBuildError(errs, err)
+	addrList := []string{""}
+	servers := map[string]*handlerServer{"": newHandlerServer(handle, a.log)}
+	for _, bind := range a.binds {
+		if _, ok := servers[bind.addr]; !ok {
+			addrList = append(addrList, bind.addr)
+			servers[bind.addr] = newHandlerServer(handle, a.log)
+			servers[bind.addr].addr = bind.addr
+		}
+	}
+	for _, path := range handle.paths {
+		server := servers[addrMap[path.child]]
+		server.paths = append(server.paths, path)
+	}
+	if a.isDocs && len(errs) == 0 {
 		openapiHandle := newHandlerOpenAPI(handle)
-		openapiMap := openapiHandle.Handle()
-		serverHandle.HandleSwagger(openapiMap)
+		for docsPath, openAPI := range openapiHandle.Handle() {
+			server := servers[docsAddrMap[docsPath]]
+			errs = appendBuildError(errs, server.HandleSwagger(map[string]*openapi.OpenAPI{docsPath: openAPI}))
+		}
+	}
+	var paths []*pathInfo
+	var routes []RouteInfo
+	for _, addr := range addrList {
+		errs = appendBuildError(errs, servers[addr].Handle())
+		paths = append(paths, servers[addr].paths...)
+		for _, path := range servers[addr].paths {
+			for _, method := range path.methods {
+				for _, p := range path.paths {
+					routes = append(routes, newRouteInfo(path, method, p, addr))
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, newBuildError(errs)
 	}
-	serverHandle.Handle()
-	return serverHandle
+	debugPrintRouter(a.log, paths)
+	a.routeMux.Lock()
+	a.namedPaths = handle.namedPaths
+	a.routes = routes
+	a.routeMux.Unlock()
+	handlers = a.swapHandlers(servers)
+	return
 }
 
 func (a *API) writeLogInfo(log Logger, format string, v ...interface{}) {
@@ -0,0 +1,173 @@
+package goapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type buildTestRouter struct{}
+
+func (b *buildTestRouter) Ping(input struct {
+	router Router `paths:"/ping" methods:"GET"`
+}) string {
+	return "pong"
+}
+
+type buildErrorRouter struct{}
+
+func (b *buildErrorRouter) Arity(a, b2, c struct{}) {}
+
+func (b *buildErrorRouter) Conflict(input struct {
+	router Router `paths:"/ping" methods:"GET"`
+}) {
+}
+
+func (b *buildErrorRouter) FileType(input struct {
+	router Router `paths:"/upload" methods:"POST"`
+	File   string `file:"file"`
+}) {
+}
+
+func TestBuildReturnsHandler(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "/api", false)
+	api.StaticFS("/static", http.Dir("."))
+	for i := 0; i < 2; i++ {
+		handler, err := api.Build()
+		if err != nil {
+			t.Fatalf("Build %v: %v", i, err)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ping", nil))
+		if w.Code != http.StatusOK || w.Body.String() != `"pong"` {
+			t.Fatalf("Build %v: got %v %q", i, w.Code, w.Body.String())
+		}
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/go.mod", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Build %v: static file got %v", i, w.Code)
+		}
+	}
+}
+
+func TestBuildCollectsAllErrors(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.SetStructTagVariableMapping(map[string]string{"{bad}": "value"})
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	api.IncludeRouter(&buildErrorRouter{}, "", false)
+	handler, err := api.Build()
+	if handler != nil {
+		t.Fatal("Build should not return a handler when there are errors")
+	}
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("Build error: got %T want *BuildError", err)
+	}
+	wants := []string{
+		"the struct tag variable mapping key cannot be within '{', '}'",
+		"the method parameters in the router must be 1 or 2, pos: " +
+			"github.com/goodluckxu-go/goapi/v2.(*buildErrorRouter).Arity",
+		"the type of parameter 'file' in 'file' must be",
+		"pos: github.com/goodluckxu-go/goapi/v2.(*buildErrorRouter).FileType",
+		"pos: github.com/goodluckxu-go/goapi/v2.(*buildErrorRouter).Conflict",
+	}
+	if len(buildErr.Errors) != 4 {
+		t.Fatalf("Build errors: got %v want 4\n%v", len(buildErr.Errors), err)
+	}
+	for _, want := range wants {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Build error should contain %q\n%v", want, err)
+		}
+	}
+}
+
+func TestRunReturnsBuildError(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	err := api.Run(freeAddr(t))
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("Run error: got %T %v want *BuildError", err, err)
+	}
+	if !strings.Contains(err.Error(), "/ping") {
+		t.Errorf("Run error should contain the duplicated router, got %v", err)
+	}
+}
+
+type constraintTestRouter struct{}
+
+func (c *constraintTestRouter) User(input struct {
+	router Router `paths:"/users/{id:int}" methods:"GET"`
+	ID     int    `path:"id"`
+}) int {
+	return input.ID
+}
+
+func (c *constraintTestRouter) Me(input struct {
+	router Router `paths:"/users/me" methods:"GET"`
+}) string {
+	return "me"
+}
+
+func (c *constraintTestRouter) Slug(input struct {
+	router Router `paths:"/tags/{slug:[a-z-]+}" methods:"GET"`
+	Slug   string `path:"slug"`
+}) string {
+	return input.Slug
+}
+
+func (c *constraintTestRouter) Post(input struct {
+	router Router `paths:"/posts/{id:uuid},/codes/{id:[0-9]{1,3}}" methods:"GET"`
+	ID     string `path:"id"`
+}) string {
+	return input.ID
+}
+
+func TestBuildRouteConstraints(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	api.IncludeRouter(&constraintTestRouter{}, "", true)
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{path: "/users/42", status: http.StatusOK, body: "42"},
+		{path: "/users/me", status: http.StatusOK, body: `"me"`},
+		{path: "/users/Foo1", status: http.StatusNotFound},
+		{path: "/tags/foo-bar", status: http.StatusOK, body: `"foo-bar"`},
+		{path: "/tags/Foo1", status: http.StatusNotFound},
+		{path: "/posts/not-a-uuid", status: http.StatusNotFound},
+		{path: "/codes/200", status: http.StatusOK, body: `"200"`},
+		{path: "/codes/2000", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%v: got %v %q want %v %q", tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	doc := w.Body.String()
+	for _, want := range []string{`"/users/{id}"`, `"/tags/{slug}"`, `"pattern":"^(?:[a-z-]+)$"`, `"format":"uuid"`} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("openapi.json should contain %v", want)
+		}
+	}
+	if strings.Contains(doc, "{id:int}") {
+		t.Error("openapi.json should not contain the constraint in the path")
+	}
+}
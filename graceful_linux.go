@@ -0,0 +1,289 @@
+//go:build linux
+
+package goapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	gracefulListenFdEnv  = "GOAPI_GRACEFUL_LISTEN_FD"
+	gracefulParentPidEnv = "GOAPI_GRACEFUL_PARENT_PID"
+	// gracefulAcceptWait is the longest wait for the accepted connections to send their first requests
+	gracefulAcceptWait = time.Second
+)
+
+// RunGraceful is the same as Run, and supports the zero-downtime binary upgrade.
+// When SIGUSR2 is received, the binary of os.Args[0] is started with the listening socket inherited.
+// The new process sends SIGTERM to the old process once it is ready, then the old process stops accepting,
+// waits for the in-flight requests to finish and returns nil.
+// SIGTERM and SIGINT gracefully shut down the server with the deadline of ServerConfig.ShutdownTimeout
+func (a *API) RunGraceful(addr ...string) (err error) {
+	if len(addr) > 0 {
+		a.addr = addr[0]
+	}
+	ln, parentPid, err := gracefulListener(a.addr)
+	if err != nil {
+		return
+	}
+	defer ln.Close()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR2, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+	done := make(chan struct{})
+	defer close(done)
+	drains := &drainListeners{}
+	go a.handleGracefulSignals(ln, drains, signals, done)
+	// the addresses of Bind are not inherited, they are bound by the new process with SO_REUSEPORT
+	a.listenBind = listenReusePort
+	return a.run("http", a.addr, func() (net.Listener, error) {
+		return ln, nil
+	}, func(server *http.Server, ln net.Listener) error {
+		return server.Serve(drains.wrap(server, ln))
+	}, func() {
+		// the old process is stopped once, after the addresses of Bind are listened too
+		if parentPid > 0 {
+			a.writeLogInfo(a.log, "Inherited listener from process [%v], stopping it", ColorDebug(strconv.Itoa(parentPid)))
+			if killErr := syscall.Kill(parentPid, syscall.SIGTERM); killErr != nil && a.log != nil {
+				a.log.Error("stop process [%v] failed: %v", parentPid, killErr)
+			}
+		}
+	})
+}
+
+// gracefulListener returns the listener inherited from the old process, or listens on addr
+func gracefulListener(addr string) (ln net.Listener, parentPid int, err error) {
+	fdStr := os.Getenv(gracefulListenFdEnv)
+	if fdStr == "" {
+		ln, err = net.Listen("tcp", addr)
+		return
+	}
+	parentPid, _ = strconv.Atoi(os.Getenv(gracefulParentPidEnv))
+	// the variables are not passed to the next upgrade
+	_ = os.Unsetenv(gracefulListenFdEnv)
+	_ = os.Unsetenv(gracefulParentPidEnv)
+	fd, err := strconv.Atoi(fdStr)
+	if err != nil {
+		return
+	}
+	file := os.NewFile(uintptr(fd), "goapi-graceful-listener")
+	defer file.Close()
+	ln, err = net.FileListener(file)
+	return
+}
+
+func (a *API) handleGracefulSignals(ln net.Listener, drains *drainListeners, signals chan os.Signal, done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case sig := <-signals:
+			if sig == syscall.SIGUSR2 {
+				if err := a.upgrade(ln); err != nil && a.log != nil {
+					a.log.Error("upgrade failed: %v", err)
+				}
+				continue
+			}
+			a.writeLogInfo(a.log, "Received signal %v", sig)
+			ctx, cancel := a.shutdownContext()
+			defer cancel()
+			drains.stop(ctx)
+			if err := a.Shutdown(ctx); err != nil && a.log != nil {
+				a.log.Error("shutdown failed: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// upgrade starts the new binary with the listening socket as the file descriptor 3
+func (a *API) upgrade(ln net.Listener) (err error) {
+	file, err := listenerFile(ln)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	path, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{file}
+	cmd.Env = append(os.Environ(),
+		gracefulListenFdEnv+"=3",
+		gracefulParentPidEnv+"="+strconv.Itoa(os.Getpid()),
+	)
+	if err = cmd.Start(); err != nil {
+		return
+	}
+	a.writeLogInfo(a.log, "Upgrading, started new process [%v]", ColorDebug(strconv.Itoa(cmd.Process.Pid)))
+	go func() {
+		// release the process resources when the new process exits before this process
+		_ = cmd.Wait()
+	}()
+	return
+}
+
+// listenerFile returns the duplicated descriptor of the listener. Unlike the File method of net.TCPListener,
+// the descriptor stays nonblocking when os/exec passes it, otherwise the socket shared with this process
+// becomes blocking, and the Accept of the server cannot be interrupted by Shutdown
+func listenerFile(ln net.Listener) (file *os.File, err error) {
+	sc, ok := ln.(syscall.Conn)
+	if !ok {
+		return nil, errors.New("the listener cannot be inherited")
+	}
+	rc, err := sc.SyscallConn()
+	if err != nil {
+		return
+	}
+	fd := -1
+	if ctrlErr := rc.Control(func(sysfd uintptr) {
+		fd, err = syscall.Dup(int(sysfd))
+	}); ctrlErr != nil {
+		return nil, ctrlErr
+	}
+	if err != nil {
+		return
+	}
+	syscall.CloseOnExec(fd)
+	return os.NewFile(uintptr(fd), "goapi-graceful-listener"), nil
+}
+
+// drainListeners It is the listeners of the servers of RunGraceful, they stop accepting before Shutdown.
+// net/http drops the requests read after Shutdown starts, so the accepted connections are waited for
+// until their first requests are handled, the new process accepts the rest of the queue of the socket
+type drainListeners struct {
+	mux  sync.Mutex
+	list []*drainListener
+}
+
+type drainListener struct {
+	net.Listener
+	mux       sync.Mutex
+	pending   map[*drainConn]struct{}
+	stopped   bool
+	stoppedOk bool // Accept has returned after stop, no more connections are pending
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+type drainConn struct {
+	net.Conn
+	ln   *drainListener
+	once sync.Once
+}
+
+type drainConnKey struct{}
+
+// wrap returns the listener served by the server, the handler of the server marks the connections handled
+func (d *drainListeners) wrap(server *http.Server, ln net.Listener) net.Listener {
+	l := &drainListener{Listener: ln, pending: map[*drainConn]struct{}{}, closed: make(chan struct{})}
+	d.mux.Lock()
+	d.list = append(d.list, l)
+	d.mux.Unlock()
+	connContext := server.ConnContext
+	server.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+		if connContext != nil {
+			ctx = connContext(ctx, c)
+		}
+		return context.WithValue(ctx, drainConnKey{}, c)
+	}
+	handler := server.Handler
+	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, ok := r.Context().Value(drainConnKey{}).(*drainConn); ok {
+			c.handled()
+		}
+		handler.ServeHTTP(w, r)
+	})
+	return l
+}
+
+// stop stops accepting, and waits for the pending connections until gracefulAcceptWait or the ctx is done
+func (d *drainListeners) stop(ctx context.Context) {
+	d.mux.Lock()
+	list := d.list
+	d.mux.Unlock()
+	for _, l := range list {
+		l.mux.Lock()
+		l.stopped = true
+		l.mux.Unlock()
+		_ = l.Listener.Close()
+	}
+	timer := time.NewTimer(gracefulAcceptWait)
+	defer timer.Stop()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for _, l := range list {
+		for !l.idle() {
+			select {
+			case <-ticker.C:
+			case <-timer.C:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (l *drainListener) idle() bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.stoppedOk && len(l.pending) == 0
+}
+
+// Accept It blocks after stop until the listener is closed by Shutdown, so that the server keeps serving
+func (l *drainListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	l.mux.Lock()
+	if l.stopped {
+		l.stoppedOk = true
+	}
+	if err != nil {
+		stopped := l.stopped
+		l.mux.Unlock()
+		if stopped {
+			<-l.closed
+			return nil, net.ErrClosed
+		}
+		return nil, err
+	}
+	c := &drainConn{Conn: conn, ln: l}
+	l.pending[c] = struct{}{}
+	l.mux.Unlock()
+	return c, nil
+}
+
+func (l *drainListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+	return l.Listener.Close()
+}
+
+// handled It is called by the first request of the connection, or when the connection is closed
+func (c *drainConn) handled() {
+	c.once.Do(func() {
+		c.ln.mux.Lock()
+		delete(c.ln.pending, c)
+		c.ln.mux.Unlock()
+	})
+}
+
+func (c *drainConn) Close() error {
+	c.handled()
+	return c.Conn.Close()
+}
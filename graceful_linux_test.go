@@ -0,0 +1,187 @@
+//go:build linux
+
+package goapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const gracefulTestAddrEnv = "GOAPI_GRACEFUL_TEST_ADDR"
+
+type gracefulTestRouter struct{}
+
+func (g *gracefulTestRouter) Pid(input struct {
+	router Router `paths:"/pid" methods:"GET"`
+}) string {
+	return strconv.Itoa(os.Getpid())
+}
+
+func (g *gracefulTestRouter) Slow(input struct {
+	router Router `paths:"/slow" methods:"GET"`
+}) string {
+	time.Sleep(time.Second)
+	return strconv.Itoa(os.Getpid())
+}
+
+// TestGracefulHelperProcess It is not a real test, it serves by RunGraceful in the processes started by
+// TestRunGracefulUpgrade, the upgraded process runs it again with the same arguments
+func TestGracefulHelperProcess(t *testing.T) {
+	addr := os.Getenv(gracefulTestAddrEnv)
+	if addr == "" {
+		return
+	}
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&gracefulTestRouter{}, "", false)
+	if err := api.RunGraceful(addr); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestRunGracefulUpgrade(t *testing.T) {
+	addr := freeAddr(t)
+	old := exec.Command(os.Args[0], "-test.run=^TestGracefulHelperProcess$")
+	old.Env = append(os.Environ(), gracefulTestAddrEnv+"="+addr)
+	if err := old.Start(); err != nil {
+		t.Fatal(err)
+	}
+	oldExited := make(chan error, 1)
+	go func() {
+		oldExited <- old.Wait()
+	}()
+	// new connections for each request, so that they are accepted by the process listening at the moment
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+	get := func(path string) (string, error) {
+		resp, err := client.Get("http://" + addr + path)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			return "", errors.New(resp.Status)
+		}
+		return string(body), err
+	}
+	oldPid := strconv.Quote(strconv.Itoa(old.Process.Pid))
+	var pid string
+	var err error
+	for i := 0; i < 200 && pid != oldPid; i++ {
+		time.Sleep(10 * time.Millisecond)
+		pid, _ = get("/pid")
+	}
+	if pid != oldPid {
+		_ = old.Process.Kill()
+		t.Fatalf("the old process is not serving, got pid %q want %q", pid, oldPid)
+	}
+	inFlight := make(chan string, 1)
+	go func() {
+		pid, err := get("/slow")
+		if err != nil {
+			pid = err.Error()
+		}
+		inFlight <- pid
+	}()
+	// make sure the slow request is accepted by the old process before the upgrade
+	time.Sleep(100 * time.Millisecond)
+	if err = old.Process.Signal(syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 500 && (pid == oldPid || pid == ""); i++ {
+		if pid, err = get("/pid"); err != nil {
+			_ = old.Process.Kill()
+			t.Fatalf("the request during the upgrade failed: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pid == oldPid {
+		_ = old.Process.Kill()
+		t.Fatal("the new process did not take over the listener")
+	}
+	newPid, _ := strconv.Atoi(pid[1 : len(pid)-1])
+	defer func() {
+		_ = syscall.Kill(newPid, syscall.SIGTERM)
+	}()
+	if got := <-inFlight; got != oldPid {
+		t.Errorf("the in-flight request should be finished by the old process, got %q", got)
+	}
+	select {
+	case err = <-oldExited:
+		if err != nil {
+			t.Errorf("the old process should exit with 0 after draining, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		_ = old.Process.Kill()
+		t.Fatal("the old process did not exit")
+	}
+	if pid, err = get("/pid"); err != nil || pid == oldPid {
+		t.Errorf("the new process should serve after the old process exits, got %q, %v", pid, err)
+	}
+}
+
+func TestRunGracefulInheritsListener(t *testing.T) {
+	parent := exec.Command("sleep", "10")
+	if err := parent.Start(); err != nil {
+		t.Skip("sleep is not available:", err)
+	}
+	parentExited := make(chan error, 1)
+	go func() {
+		parentExited <- parent.Wait()
+	}()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	t.Setenv(gracefulListenFdEnv, strconv.Itoa(int(file.Fd())))
+	t.Setenv(gracefulParentPidEnv, strconv.Itoa(parent.Process.Pid))
+
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.RunGraceful()
+	}()
+	select {
+	case err = <-parentExited:
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.Sys().(syscall.WaitStatus).Signal() != syscall.SIGTERM {
+			t.Fatalf("the old process should be stopped by SIGTERM, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		_ = parent.Process.Kill()
+		t.Fatal("the old process was not stopped")
+	}
+	resp, err := http.Get("http://" + addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status: got %v", resp.StatusCode)
+	}
+	if err = api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-runErr; err != nil {
+		t.Fatalf("RunGraceful after Shutdown: got %v want nil", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package goapi
+
+import (
+	"errors"
+)
+
+// RunGraceful is the same as Run, and supports the zero-downtime binary upgrade on linux only
+func (a *API) RunGraceful(addr ...string) (err error) {
+	return errors.New("RunGraceful is only supported on linux")
+}
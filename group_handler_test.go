@@ -0,0 +1,77 @@
+package goapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type groupHandlerTestRouter struct{}
+
+func (r *groupHandlerTestRouter) Fail(input struct {
+	router Router `paths:"/fail" methods:"GET"`
+}) (string, error) {
+	return "", NewHTTPError(http.StatusBadRequest, "failed")
+}
+
+type groupTestError struct {
+	Code  int    `json:"code"`
+	Group string `json:"group"`
+}
+
+func (g groupTestError) GetStatus() int {
+	return g.Code
+}
+
+func TestGroupHandlers(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	api.HandleMethodNotAllowed = true
+	api.IncludeRouter(&groupHandlerTestRouter{}, "", false)
+	group := api.Group("/api", true)
+	group.HTTPError(func(err error) any {
+		return groupTestError{Code: http.StatusTeapot, Group: "api"}
+	})
+	group.NoRoute(func(ctx *Context) {
+		ctx.Writer.WriteHeader(http.StatusNotFound)
+		_, _ = ctx.Writer.Write([]byte("api not found"))
+	})
+	group.NoMethod(func(ctx *Context) {
+		ctx.Writer.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = ctx.Writer.Write([]byte("api not method"))
+	})
+	group.IncludeRouter(&groupHandlerTestRouter{}, "", true)
+	web := group.Group("/web", false)
+	web.NoRoute(func(ctx *Context) {
+		ctx.Writer.WriteHeader(http.StatusNotFound)
+		_, _ = ctx.Writer.Write([]byte("web not found"))
+	})
+	web.IncludeRouter(&groupHandlerTestRouter{}, "", false)
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{method: http.MethodGet, path: "/fail", code: http.StatusBadRequest, body: "failed"},
+		{method: http.MethodGet, path: "/api/fail", code: http.StatusTeapot, body: `"group":"api"`},
+		{method: http.MethodGet, path: "/api/web/fail", code: http.StatusTeapot, body: `"group":"api"`},
+		{method: http.MethodGet, path: "/none", code: http.StatusNotFound, body: "404 page not found"},
+		{method: http.MethodGet, path: "/api/none", code: http.StatusNotFound, body: "api not found"},
+		{method: http.MethodGet, path: "/api/web/none", code: http.StatusNotFound, body: "web not found"},
+		{method: http.MethodPost, path: "/api/web/fail", code: http.StatusMethodNotAllowed, body: "api not method"},
+		{method: http.MethodGet, path: "/docs/openapi.json", code: http.StatusOK, body: `"group":{`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%v %v: got %v %q want %v %q", tt.method, tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package goapi
 import (
 	"fmt"
 	"io"
-	"log"
 	"math"
 	"net/http"
 	"net/textproto"
@@ -27,7 +26,9 @@ func newHandler(api *API) *handler {
 		openapiMap:             map[string]*openapi.OpenAPI{},
 		swaggerMap:             map[string]swagger.Config{},
 		errorMap:               map[string]*errorInfo{},
+		groupMap:               map[string]returnObjGroup{},
 		langMap:                map[string]Lang{},
+		namedPaths:             map[string]*pathInfo{},
 	}
 }
 
@@ -41,12 +42,15 @@ type handler struct {
 	openapiMap             map[string]*openapi.OpenAPI
 	swaggerMap             map[string]swagger.Config
 	childMap               map[string]returnObjChild
-	errorMap               map[string]*errorInfo
+	namedPaths             map[string]*pathInfo      // the routers with the 'name' tag
+	errorMap               map[string]*errorInfo     // child path
+	groupMap               map[string]returnObjGroup // group prefix, the groups with HTTPError, NoRoute or NoMethod
+	errorList              []*errorInfo              // the HTTPError of the children and the groups
 	langList               []Lang
 	langMap                map[string]Lang
 }
 
-func (h *handler) Handle() {
+func (h *handler) Handle() (err error) {
 	h.langList = h.api.langList
 	if len(h.langList) == 0 {
 		h.langList = []Lang{&lang.EnUs{}}
@@ -54,13 +58,21 @@ func (h *handler) Handle() {
 	for _, val := range h.langList {
 		h.langMap[val.Abbr()] = val
 	}
+	errs := appendBuildError(nil, h.api.structTagVariableErr)
+	errs = append(errs, h.api.middlewareErrs...)
+	errs = appendBuildError(errs, h.api.providers.resolve())
 	obj, err := h.api.returnObj()
-	if err != nil {
-		log.Fatal(err)
-	}
+	errs = appendBuildError(errs, err)
 	for k, v := range obj.groupMap {
 		if len(v.middlewares) > 0 {
-			h.publicGroupMiddlewares[k] = append(h.publicGroupMiddlewares[k], v.middlewares...)
+			middlewares, mErr := h.resolveMiddlewares(v.middlewares, nil)
+			if mErr != nil {
+				errs = append(errs, fmt.Errorf("%v, group: %v", mErr, k))
+			}
+			h.publicGroupMiddlewares[k] = append(h.publicGroupMiddlewares[k], middlewares...)
+		}
+		if v.errorInfo != nil || v.noRoute != nil || v.noMethod != nil {
+			h.groupMap[k] = v
 		}
 	}
 	h.childMap = obj.childMap
@@ -68,13 +80,18 @@ func (h *handler) Handle() {
 		h.errorMap[k] = &errorInfo{
 			errorFunc: v.errorFunc,
 		}
+		h.errorList = append(h.errorList, h.errorMap[k])
 	}
 	for k, v := range obj.docsMap {
 		if !v.isDocs {
 			continue
 		}
-		v.info.Summary = h.getMappingTag(v.info.Summary)
-		v.info.Description = h.getMappingTag(v.info.Description)
+		if v.info.Summary, err = h.getMappingTag(v.info.Summary); err != nil {
+			errs = append(errs, fmt.Errorf("%v, docs: %v", err, k))
+		}
+		if v.info.Description, err = h.getMappingTag(v.info.Description); err != nil {
+			errs = append(errs, fmt.Errorf("%v, docs: %v", err, k))
+		}
 		h.openapiMap[k] = &openapi.OpenAPI{
 			Info:    v.info,
 			Servers: v.servers,
@@ -85,133 +102,31 @@ func (h *handler) Handle() {
 	for k, v := range obj.mediaTypes {
 		h.mediaTypes[k] = v
 	}
-	h.paths = obj.paths
-	var field *paramField
-	for _, path := range h.paths {
-		if path.inFs != nil {
+	h.paths = make([]*pathInfo, 0, len(obj.paths))
+	for _, path := range obj.paths {
+		if path.middlewares, err = h.resolveMiddlewares(path.middlewareInfos, path.skipMiddlewares); err != nil {
+			errs = append(errs, fmt.Errorf("%v, pos: %v", err, path.pos))
 			continue
 		}
-		path.desc = h.getMappingTag(path.desc)
-		path.summary = h.getMappingTag(path.summary)
-		for key, in := range path.inParams {
-			if in.inType == inTypeCtx {
-				path.existsCtx = true
-			}
-			if in.parentInType != "" && !inArray(in.inType, []InType{inTypeQuery, inTypeHeader, inTypeCookie, inTypeCtx}) {
-				log.Fatal("only 'query','header' and 'cookie' can be passed into interface security")
-			}
-			if in.inType == inTypeFile {
-				if !isArrayType(in.structField.Type, func(sType reflect.Type) bool {
-					if sType.ConvertibleTo(typeFile) {
-						return true
-					}
-					return false
-				}, 2) {
-					log.Fatalf("the type of parameter '%v' in '%v' must be "+
-						"‘*multipart.FileHeader’ or an array of ‘*multipart.FileHeader’, has type '%v'",
-						in.values[0].name, in.inType.Tag(),
-						in.structField.Type.String())
-				}
-			} else if in.inType == inTypeBody {
-				m := map[uint8]struct{}{}
-				var mList []string
-				for _, val := range in.values {
-					if val.mediaType.IsStream() {
-						// stream
-						m[1] = struct{}{}
-					} else {
-						// no stream
-						m[0] = struct{}{}
-					}
-					mv := "'" + string(val.mediaType) + "'"
-					if !inArray(mv, mList) {
-						mList = append(mList, mv)
-					}
-				}
-				if len(m) != 1 {
-					log.Fatalf("Content-Type %v cannot be used together", strings.Join(mList, ", "))
-				}
-				if _, ok := m[1]; ok {
-					// stream
-					vType := in.structField.Type
-					for vType.Kind() == reflect.Ptr {
-						vType = vType.Elem()
-					}
-					if !(vType.ConvertibleTo(typeBytes) || vType.Kind() == reflect.String || vType == typeReadCloser) {
-						log.Fatal("other media types only support types '[]byte', 'string', and 'io.ReadCloser‘")
-					}
-					in.field = &paramField{
-						meta: &paramMeta{},
-					}
-					fType := removeMorePtr(in.structField.Type)
-					in.field.kind = fType.Kind()
-					if fType.Kind() == reflect.Ptr {
-						in.field.kind = fType.Elem().Kind()
-					}
-					fVal := getValueByType(fType, true)
-					if err = h.handleMetaByInterface(fType, in.field, fVal); err != nil {
-						log.Fatal(err)
-					}
-					if fType.Kind() == reflect.Ptr {
-						fType = fType.Elem()
-					}
-					in.field._type = fType
-					if err = h.handleMetaByField(in.structField, in.field); err != nil {
-						log.Fatal(err)
-					}
-				} else {
-					// no stream
-					field, err = h.handleField(in.structField, -1, true)
-					if err != nil {
-						log.Fatal(err)
-					}
-					field.anonymous = true
-					in.field = field
-				}
-				path.inParams[key] = in
-				continue
-			} else if in.inType.IsSingle() {
-				if !isArrayType(in.structField.Type, func(sType reflect.Type) bool {
-					if isNormalType(sType) {
-						return true
-					}
-					if in.inType == inTypeCookie && sType.ConvertibleTo(typeCookie) {
-						return true
-					}
-					if isTextInterface(sType) {
-						return true
-					}
-					return false
-				}, 2) {
-					log.Fatal(fmt.Errorf("the type of parameter '%v' in '%v' cannot be '%v'", in.values[0].name,
-						in.inType.Tag(), in.structField.Type.String()))
-				}
-			}
-			field, err = h.handleParam(in.inType, in.structField, -1, in.values)
-			if err != nil {
-				log.Fatal(err)
-			}
-			in.field = field
-			path.inParams[key] = in
+		if err = h.handlePathParams(path); err != nil {
+			errs = append(errs, fmt.Errorf("%v, pos: %v", err, path.pos))
+			continue
 		}
-		if path.outParam != nil {
-			field = &paramField{
-				meta:  &paramMeta{},
-				_type: path.outParam.structField.Type,
-			}
-			path.outParam.httpStatus = http.StatusOK
-			h.handleOutParam(path.outParam)
-			if _, ok := getTypeByCovertInterface[io.ReadCloser](path.outParam.structField.Type); !ok &&
-				path.outParam.structField.Type != nil {
-				field, err = h.handleField(path.outParam.structField, -1, true)
-				if err != nil {
-					log.Fatal(err)
-				}
-			}
-			path.outParam.field = field
+		h.paths = append(h.paths, path)
+		if path.errorInfo != nil && !inArray(path.errorInfo, h.errorList) {
+			h.errorList = append(h.errorList, path.errorInfo)
+		}
+		if path.name == "" {
+			continue
+		}
+		if named, ok := h.namedPaths[path.name]; ok {
+			errs = append(errs, fmt.Errorf("the router name '%v' is duplicated, pos: %v and %v", path.name, named.pos, path.pos))
+			continue
 		}
+		h.namedPaths[path.name] = path
 	}
-	for _, item := range h.errorMap {
+	var field *paramField
+	for _, item := range h.errorList {
 		if item.errorFunc != nil {
 			item.outParam = &outParam{
 				httpStatus: http.StatusOK,
@@ -230,18 +145,22 @@ func (h *handler) Handle() {
 			item.outParam.structField = reflect.StructField{Type: fType}
 			field, err = h.handleField(item.outParam.structField, -1, true)
 			if err != nil {
-				log.Fatal(err)
+				errs = append(errs, err)
+				item.errorFunc = nil
+				continue
 			}
 			item.outParam.field = field
 		}
 	}
-	err = h.handleStruct()
-	if err != nil {
-		log.Fatal(err)
+	if err = h.handleStruct(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return newBuildError(errs)
 	}
 	// handle other
 	for _, path := range h.paths {
-		if path.inFs != nil {
+		if path.inFs != nil || path.handle != nil {
 			continue
 		}
 		for _, in := range path.inParams {
@@ -274,10 +193,10 @@ func (h *handler) Handle() {
 		}
 		path.extensions, err = h.handleExtensions(path.value.Type())
 		if err != nil {
-			log.Fatalf("%v, pos: %v", err, path.pos)
+			errs = append(errs, fmt.Errorf("%v, pos: %v", err, path.pos))
 		}
 	}
-	for _, item := range h.errorMap {
+	for _, item := range h.errorList {
 		if item.errorFunc != nil {
 			if _, ok := getTypeByCovertInterface[io.ReadCloser](item.outParam.structField.Type); !ok &&
 				item.outParam.structField.Type != nil && !item.outParam.field.isTextType {
@@ -290,6 +209,150 @@ func (h *handler) Handle() {
 		}
 	}
 	h.handleOpenapiName()
+	return newBuildError(errs)
+}
+
+// getErrorInfo returns the HTTPError of the nearest group of the router, or the HTTPError of the child
+func (h *handler) getErrorInfo(path *pathInfo) *errorInfo {
+	if path.errorInfo != nil {
+		return path.errorInfo
+	}
+	return h.errorMap[path.childPath]
+}
+
+// handlePathParams analyzes the input and output parameters of a router
+func (h *handler) handlePathParams(path *pathInfo) (err error) {
+	if path.inFs != nil {
+		return
+	}
+	if path.desc, err = h.getMappingTag(path.desc); err != nil {
+		return
+	}
+	if path.summary, err = h.getMappingTag(path.summary); err != nil {
+		return
+	}
+	var field *paramField
+	for key, in := range path.inParams {
+		if in.inType == inTypeCtx {
+			path.existsCtx = true
+		}
+		if in.parentInType != "" && !inArray(in.inType, []InType{inTypeQuery, inTypeHeader, inTypeCookie, inTypeCtx}) {
+			return fmt.Errorf("only 'query','header' and 'cookie' can be passed into interface security")
+		}
+		if in.inType == inTypeDepend {
+			continue
+		}
+		if in.inType == inTypeFile {
+			if !isArrayType(in.structField.Type, func(sType reflect.Type) bool {
+				if sType.ConvertibleTo(typeFile) {
+					return true
+				}
+				return false
+			}, 2) {
+				return fmt.Errorf("the type of parameter '%v' in '%v' must be "+
+					"‘*multipart.FileHeader’ or an array of ‘*multipart.FileHeader’, has type '%v'",
+					in.values[0].name, in.inType.Tag(),
+					in.structField.Type.String())
+			}
+		} else if in.inType == inTypeBody {
+			m := map[uint8]struct{}{}
+			var mList []string
+			for _, val := range in.values {
+				if val.mediaType.IsStream() {
+					// stream
+					m[1] = struct{}{}
+				} else {
+					// no stream
+					m[0] = struct{}{}
+				}
+				mv := "'" + string(val.mediaType) + "'"
+				if !inArray(mv, mList) {
+					mList = append(mList, mv)
+				}
+			}
+			if len(m) != 1 {
+				return fmt.Errorf("Content-Type %v cannot be used together", strings.Join(mList, ", "))
+			}
+			if _, ok := m[1]; ok {
+				// stream
+				vType := in.structField.Type
+				for vType.Kind() == reflect.Ptr {
+					vType = vType.Elem()
+				}
+				if !(vType.ConvertibleTo(typeBytes) || vType.Kind() == reflect.String || vType == typeReadCloser) {
+					return fmt.Errorf("other media types only support types '[]byte', 'string', and 'io.ReadCloser‘")
+				}
+				in.field = &paramField{
+					meta: &paramMeta{},
+				}
+				fType := removeMorePtr(in.structField.Type)
+				in.field.kind = fType.Kind()
+				if fType.Kind() == reflect.Ptr {
+					in.field.kind = fType.Elem().Kind()
+				}
+				fVal := getValueByType(fType, true)
+				if err = h.handleMetaByInterface(fType, in.field, fVal); err != nil {
+					return
+				}
+				if fType.Kind() == reflect.Ptr {
+					fType = fType.Elem()
+				}
+				in.field._type = fType
+				if err = h.handleMetaByField(in.structField, in.field); err != nil {
+					return
+				}
+			} else {
+				// no stream
+				field, err = h.handleField(in.structField, -1, true)
+				if err != nil {
+					return
+				}
+				field.anonymous = true
+				in.field = field
+			}
+			path.inParams[key] = in
+			continue
+		} else if in.inType.IsSingle() {
+			if !isArrayType(in.structField.Type, func(sType reflect.Type) bool {
+				if isNormalType(sType) {
+					return true
+				}
+				if in.inType == inTypeCookie && sType.ConvertibleTo(typeCookie) {
+					return true
+				}
+				if isTextInterface(sType) {
+					return true
+				}
+				return false
+			}, 2) {
+				return fmt.Errorf("the type of parameter '%v' in '%v' cannot be '%v'", in.values[0].name,
+					in.inType.Tag(), in.structField.Type.String())
+			}
+		}
+		field, err = h.handleParam(in.inType, in.structField, -1, in.values)
+		if err != nil {
+			return
+		}
+		in.field = field
+		path.inParams[key] = in
+	}
+	if path.outParam != nil {
+		field = &paramField{
+			meta:  &paramMeta{},
+			_type: path.outParam.structField.Type,
+		}
+		path.outParam.httpStatus = http.StatusOK
+		h.handleOutParam(path.outParam)
+		if _, ok := getTypeByCovertInterface[io.ReadCloser](path.outParam.structField.Type); !ok &&
+			path.outParam.structField.Type != nil {
+			field, err = h.handleField(path.outParam.structField, -1, true)
+			if err != nil {
+				return
+			}
+		}
+		path.outParam.field = field
+	}
+	return
 }
 
 func (h *handler) setExample(val reflect.Value, field *paramField, onlyFind bool, useStructMaps ...map[string]struct{}) (isNoSupport bool) {
@@ -771,7 +834,9 @@ func (h *handler) handleMetaByInterface(fType reflect.Type, field *paramField, v
 		field.meta.unique = iMeta.Unique()
 	}
 	if iMeta, ok := val.(MetaDesc); ok {
-		field.meta.desc = h.getMappingTag(iMeta.Desc())
+		if field.meta.desc, err = h.getMappingTag(iMeta.Desc()); err != nil {
+			return
+		}
 	}
 	if iMeta, ok := val.(MetaDefault); ok {
 		fVal := reflect.New(field._type)
@@ -855,7 +920,9 @@ func (h *handler) handleMetaByField(field reflect.StructField, pField *paramFiel
 		}
 	}
 	if tagVal := field.Tag.Get(tagDesc); tagVal != "" {
-		pField.meta.desc = h.getMappingTag(tagVal)
+		if pField.meta.desc, err = h.getMappingTag(tagVal); err != nil {
+			return
+		}
 	}
 	if tagVal := field.Tag.Get(tagDefault); tagVal != "" {
 		if err = h.parseMetaValByField(tagVal, &pField.meta._default, pField); err != nil {
@@ -879,7 +946,7 @@ func (h *handler) handleMetaByField(field reflect.StructField, pField *paramFiel
 		}
 	}
 	// handle extensions
-	pField.meta.extensions = h.getExtensionsTags(string(field.Tag))
+	pField.meta.extensions, err = h.getExtensionsTags(string(field.Tag))
 	return
 }
 
@@ -904,7 +971,10 @@ func (h *handler) handleExtensions(fType reflect.Type) (extensions map[string][]
 
 	for i := 0; i < numField; i++ {
 		field := stType.Field(i)
-		extends := h.getExtensionsTags(string(field.Tag))
+		var extends map[string]any
+		if extends, err = h.getExtensionsTags(string(field.Tag)); err != nil {
+			return
+		}
 		for k, v := range extends {
 			vStr, _ := v.(string)
 			extensions[k] = append(extensions[k], vStr)
@@ -921,7 +991,7 @@ func (h *handler) setExtensionsPrefix(prefix *string, name string) {
 	*prefix = *prefix + "." + name
 }
 
-func (h *handler) getExtensionsTags(tag string) (extensions map[string]any) {
+func (h *handler) getExtensionsTags(tag string) (extensions map[string]any, err error) {
 	extensions = map[string]any{}
 	for tag != "" {
 		// Skip leading space.
@@ -963,12 +1033,12 @@ func (h *handler) getExtensionsTags(tag string) (extensions map[string]any) {
 		tag = tag[i+1:]
 
 		if len(name) > 2 && name[:2] == "x-" {
-			value, err := strconv.Unquote(qValue)
-			if err != nil {
+			value, unquoteErr := strconv.Unquote(qValue)
+			if unquoteErr != nil {
 				break
 			}
 			if name == "x-match" {
-				log.Fatal("'x-match' is system defined and cannot be customized")
+				err = fmt.Errorf("'x-match' is system defined and cannot be customized")
 				return
 			}
 			extensions[name] = value
@@ -1230,16 +1300,16 @@ func (h *handler) getShortPkgName(pkgName string) (long, short string) {
 	return
 }
 
-func (h *handler) getMappingTag(tagVal string, replaces ...map[string]struct{}) string {
+func (h *handler) getMappingTag(tagVal string, replaces ...map[string]struct{}) (newTagVal string, err error) {
 	if tagVal == "" {
-		return tagVal
+		return tagVal, nil
 	}
 	replace := map[string]struct{}{}
 	if len(replaces) > 0 {
 		replace = replaces[0]
 	}
 	tagList := h.handleMappingTag(tagVal)
-	newTagVal := ""
+	var mappingVal string
 	for _, tag := range tagList {
 		c := tag[0]
 		tag = tag[1:]
@@ -1248,15 +1318,19 @@ func (h *handler) getMappingTag(tagVal string, replaces ...map[string]struct{})
 			val := h.api.structTagVariableMap[oldVal]
 			if val != nil {
 				if _, ok := replace[oldVal]; ok {
-					log.Fatalf("mapping tag '%v' dead loop", oldVal)
+					err = fmt.Errorf("mapping tag '%v' dead loop", oldVal)
+					return
 				}
-				newTagVal += h.getMappingTag(val.(string), h.cloneMappingAppend(replace, oldVal))
+				if mappingVal, err = h.getMappingTag(val.(string), h.cloneMappingAppend(replace, oldVal)); err != nil {
+					return
+				}
+				newTagVal += mappingVal
 				continue
 			}
 		}
 		newTagVal += tag
 	}
-	return newTagVal
+	return
 }
 
 func (h *handler) cloneMappingAppend(m map[string]struct{}, key string) map[string]struct{} {
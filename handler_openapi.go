@@ -63,7 +63,7 @@ func (h *handlerOpenAPI) handleStructs() {
 		if path.outParam != nil {
 			h.handlePkgNameMediaTypes(path.docsPath, path.outParam.field, responseMediaTypes)
 		}
-		er := h.handle.errorMap[path.childPath]
+		er := h.handle.getErrorInfo(path)
 		if er != nil && er.outParam != nil {
 			h.handlePkgNameMediaTypes(path.docsPath, er.outParam.field, responseMediaTypes)
 		}
@@ -393,12 +393,28 @@ func (h *handlerOpenAPI) handleXmlExample(val *any) {
 
 func (h *handlerOpenAPI) handlePath(path *pathInfo) {
 	openAPI := h.handle.openapiMap[path.docsPath]
+	if path.mountDocs != nil {
+		if !path.isDocs {
+			return
+		}
+		if openAPI.Paths == nil {
+			openAPI.Paths = &openapi.Paths{}
+		}
+		pathItem := openAPI.Paths.Value(path.paths[0])
+		if pathItem == nil {
+			pathItem = &openapi.PathItem{}
+		}
+		mergePathItem(pathItem, *path.mountDocs)
+		openAPI.Paths.Set(path.paths[0], pathItem)
+		return
+	}
 	for _, p := range path.paths {
 		if !path.isDocs {
 			continue
 		}
 		h.handleSecuritySchemes(openAPI, path)
-		setPath, pathName, isMatchAll := h.getMatchAllPath(p)
+		constraintPath, constraints := h.getConstraintPath(p)
+		setPath, pathName, isMatchAll := h.getMatchAllPath(constraintPath)
 		if openAPI.Paths == nil {
 			openAPI.Paths = &openapi.Paths{}
 		}
@@ -406,6 +422,9 @@ func (h *handlerOpenAPI) handlePath(path *pathInfo) {
 		if pathItem == nil {
 			pathItem = &openapi.PathItem{}
 		}
+		if path.host != "" {
+			pathItem.Servers = h.getHostServers(path)
+		}
 		for _, method := range path.methods {
 			lowerMethod := strings.ToLower(method)
 			operation := &openapi.Operation{}
@@ -435,7 +454,7 @@ func (h *handlerOpenAPI) handlePath(path *pathInfo) {
 				pathItem.AdditionalOperations[lowerMethod] = operation
 			}
 			operation.OperationId = fmt.Sprintf("%v%v", lowerMethod, strings.ReplaceAll(setPath, "/", "_"))
-			h.handleOperation(operation, path, setPath, pathName, isMatchAll)
+			h.handleOperation(operation, path, setPath, pathName, isMatchAll, constraints)
 		}
 		openAPI.Paths.Set(setPath, pathItem)
 	}
@@ -496,7 +515,8 @@ func (h *handlerOpenAPI) handleSecuritySchemes(openAPI *openapi.OpenAPI, path *p
 	}
 }
 
-func (h *handlerOpenAPI) handleOperation(operation *openapi.Operation, path *pathInfo, setPath, pathName string, isMatchAll bool) {
+func (h *handlerOpenAPI) handleOperation(operation *openapi.Operation, path *pathInfo, setPath, pathName string, isMatchAll bool,
+	constraints map[string]*routeConstraint) {
 	operation.Tags = path.tags
 	operation.Summary = path.summary
 	operation.Description = path.desc
@@ -529,6 +549,14 @@ func (h *handlerOpenAPI) handleOperation(operation *openapi.Operation, path *pat
 			}
 			schema := &openapi.Schema{}
 			h.handleParamField(schema, in.field, "", "")
+			if constraint := constraints[name.name]; in.inType == inTypePath && constraint != nil && schema.Type == "string" {
+				if schema.Pattern == "" {
+					schema.Pattern = constraint.pattern
+				}
+				if schema.Format == "" {
+					schema.Format = constraint.format
+				}
+			}
 			var extensions map[string]any
 			if swagger.ShowExtensions {
 				extensions = in.field.meta.extensions
@@ -665,7 +693,7 @@ func (h *handlerOpenAPI) handleOperation(operation *openapi.Operation, path *pat
 			Headers:     header,
 		}
 	}
-	er := h.handle.errorMap[path.childPath]
+	er := h.handle.getErrorInfo(path)
 	if er != nil && er.outParam != nil {
 		resContentMap := map[string]*openapi.MediaType{}
 		contentType := er.outParam.httpHeader.Get("Content-Type")
@@ -729,10 +757,62 @@ func (h *handlerOpenAPI) handleOperation(operation *openapi.Operation, path *pat
 	}
 }
 
+// getHostServers returns the servers of the host, the wildcards of the host are the server variables
+func (h *handlerOpenAPI) getHostServers(path *pathInfo) []*openapi.Server {
+	pattern, err := newHostPattern(path.host)
+	if err != nil {
+		return nil
+	}
+	server := &openapi.Server{
+		URL: "{scheme}://" + pattern.docsHost,
+		Variables: map[string]*openapi.ServerVariable{
+			"scheme": {Enum: []string{"https", "http"}, Default: "https"},
+		},
+	}
+	for _, name := range pattern.names {
+		variable := &openapi.ServerVariable{Default: name}
+		for _, in := range path.inParams {
+			if in.inType != inTypeHost || in.values[0].name != name {
+				continue
+			}
+			variable.Description = in.field.meta.desc
+			if in.field.meta.example != nil {
+				variable.Default = toString(in.field.meta.example)
+			}
+		}
+		server.Variables[name] = variable
+	}
+	return []*openapi.Server{server}
+}
+
 func (h *handlerOpenAPI) isParamPath(name string, setPath string) (ok bool) {
 	return strings.Contains(setPath, "{"+name+"}")
 }
 
+// getConstraintPath removes the constraints of the param wildcards from the path, such as '/users/{id:int}' to '/users/{id}'
+func (h *handlerOpenAPI) getConstraintPath(fullPath string) (setPath string, constraints map[string]*routeConstraint) {
+	n := &node{}
+	constraints = map[string]*routeConstraint{}
+	for fullPath != "" {
+		wildcard, i, valid := n.findWildcard(fullPath)
+		if i < 0 || !valid || len(wildcard) < 3 {
+			break
+		}
+		setPath += fullPath[:i]
+		fullPath = fullPath[i+len(wildcard):]
+		name, nType := n.parseWildcard(wildcard)
+		constraint, _ := n.parseConstraint(wildcard)
+		if nType == catchAll || constraint == nil {
+			setPath += wildcard
+			continue
+		}
+		constraints[name] = constraint
+		setPath += "{" + name + "}"
+	}
+	setPath += fullPath
+	return
+}
+
 func (h *handlerOpenAPI) getMatchAllPath(fullPath string) (setPath, pathName string, isMatchAll bool) {
 	setPath = fullPath
 	lastIdx := len(fullPath) - 1
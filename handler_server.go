@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"mime"
 	"mime/multipart"
 	"net/http"
@@ -43,37 +42,44 @@ func newHandlerServer(handle *handler, log Logger) *handlerServer {
 type handlerServer struct {
 	log             Logger
 	handle          *handler
+	addr            string      // the address of Bind, empty for the address of Run
+	paths           []*pathInfo // the routers served by this server, see API.Bind
 	trees           methodTrees
+	hostTrees       []*hostTree // the routers of the hosts, the exact hosts are in front
 	pool            sync.Pool
 	regexpCache     sync.Map // map[string]*regexp.Regexp, Cache compiled regular expressions to avoid repeated compilation
 	maxParams       uint16
 	maxSkippedNodes uint16
 }
 
-func (h *handlerServer) Handle() {
-	for _, path := range h.handle.paths {
-		h.handlePath(path)
+func (h *handlerServer) Handle() error {
+	var errs []error
+	for _, path := range h.paths {
+		errs = appendBuildError(errs, h.handlePath(path))
 	}
-	debugPrintRouter(h.log, h.handle.paths)
+	return newBuildError(errs)
 }
 
-func (h *handlerServer) HandleSwagger(openapiMap map[string]*openapi.OpenAPI) {
+func (h *handlerServer) HandleSwagger(openapiMap map[string]*openapi.OpenAPI) error {
 	pos := runtime.FuncForPC(reflect.ValueOf(swagger.GetSwagger).Pointer()).Name()
+	var errs []error
 	for docsPath, openAPI := range openapiMap {
 		if err := openAPI.Validate(); err != nil {
-			log.Fatal(err)
+			errs = append(errs, fmt.Errorf("%v, docs: %v", err, docsPath))
+			continue
 		}
 		openapiBody, _ := json.Marshal(openAPI)
 		routers := swagger.GetSwagger(docsPath, openAPI.Info.Title, openapiBody, h.handle.swaggerMap[docsPath])
 		for _, router := range routers {
-			h.handleSwagger(router, pos)
+			h.handleSwagger(router, docsPath, pos)
 		}
 	}
+	return newBuildError(errs)
 }
 
-func (h *handlerServer) handleSwagger(router swagger.Router, pos string) {
+func (h *handlerServer) handleSwagger(router swagger.Router, docsPath, pos string) {
 	middlewares := h.getMiddlewares(router.Paths[0])
-	h.handle.paths = append(h.handle.paths, &pathInfo{
+	h.paths = append(h.paths, &pathInfo{
 		paths:       router.Paths,
 		methods:     []string{http.MethodGet},
 		middlewares: middlewares,
@@ -81,28 +87,46 @@ func (h *handlerServer) handleSwagger(router swagger.Router, pos string) {
 			router.Handler(ctx.Writer, ctx.Request)
 		},
 		pos:       pos,
+		docsPath:  docsPath,
 		isSwagger: true,
 	})
 }
 
-func (h *handlerServer) handlePath(path *pathInfo) {
+func (h *handlerServer) handlePath(path *pathInfo) error {
 	var handleFunc HandleFunc
 	if path.inFs != nil {
 		handleFunc = h.handleStaticFS(path)
 	} else {
 		handleFunc = h.handleRouter(path)
 	}
+	trees := &h.trees
+	var hostNames []string
+	if path.host != "" {
+		tree, err := h.getHostTree(path.host)
+		if err != nil {
+			return fmt.Errorf("%v, pos: %v", err, path.pos)
+		}
+		trees = &tree.trees
+		hostNames = tree.host.names
+	}
+	for _, in := range path.inParams {
+		if in.inType == inTypeHost && !inArray(in.values[0].name, hostNames) {
+			return fmt.Errorf("the host parameter '%v' has no wildcard '{%v}' in the host '%v', pos: %v",
+				in.values[0].name, in.values[0].name, path.host, path.pos)
+		}
+	}
+	var errs []error
 	for _, method := range path.methods {
-		root := h.trees.get(method)
+		root := trees.get(method)
 		if root == nil {
 			root = &node{}
-			h.trees = append(h.trees, methodTree{
+			*trees = append(*trees, methodTree{
 				method: method,
 				root:   root,
 			})
 		}
 		for _, p := range path.paths {
-			maxParams := countParams(p)
+			maxParams := countParams(p) + countParams(path.host)
 			if h.maxParams < maxParams {
 				h.maxParams = maxParams
 			}
@@ -112,10 +136,35 @@ func (h *handlerServer) handlePath(path *pathInfo) {
 			}
 			err := root.addRoute(p, handleFunc)
 			if err != nil {
-				log.Fatal(fmt.Errorf("%v, pos: %v", err, path.pos))
+				errs = append(errs, fmt.Errorf("%v, pos: %v", err, path.pos))
 			}
 		}
 	}
+	return newBuildError(errs)
+}
+
+// getHostTree returns the routers of the host, it is created if not exists
+func (h *handlerServer) getHostTree(host string) (tree *hostTree, err error) {
+	for _, tree = range h.hostTrees {
+		if tree.host.pattern == host {
+			return
+		}
+	}
+	pattern, err := newHostPattern(host)
+	if err != nil {
+		return nil, err
+	}
+	tree = &hostTree{host: pattern, trees: make(methodTrees, 0, 8)}
+	i := len(h.hostTrees)
+	if pattern.regexp == nil {
+		for i > 0 && h.hostTrees[i-1].host.regexp != nil {
+			i--
+		}
+	}
+	h.hostTrees = append(h.hostTrees, nil)
+	copy(h.hostTrees[i+1:], h.hostTrees[i:])
+	h.hostTrees[i] = tree
+	return
 }
 
 func (h *handlerServer) handleStaticPath(path string) string {
@@ -148,6 +197,10 @@ func (h *handlerServer) handleStaticFS(path *pathInfo) HandleFunc {
 }
 
 func (h *handlerServer) handleRouter(path *pathInfo) HandleFunc {
+	// Pre-build the binding plan to avoid deriving the inputs by reflection for each request
+	if path.bind == nil && path.handle == nil && len(path.inTypes) > 0 {
+		path.bind = h.compileBindPlan(path)
+	}
 	// Pre-build handlers slices to avoid making +copy for each request
 	if path.handlersWithExec == nil {
 		path.handlersWithExec = make([]HandleFunc, len(path.middlewares)+1)
@@ -169,8 +222,8 @@ func (h *handlerServer) handleRouter(path *pathInfo) HandleFunc {
 
 func (h *handlerServer) handleError(ctx *Context, err error) {
 	var errorFunc func(err error) any
-	if h.handle.errorMap[ctx.ChildPath] != nil {
-		errorFunc = h.handle.errorMap[ctx.ChildPath].errorFunc
+	if info := h.getErrorInfo(ctx); info != nil {
+		errorFunc = info.errorFunc
 	}
 	if errorFunc == nil {
 		return
@@ -179,6 +232,35 @@ func (h *handlerServer) handleError(ctx *Context, err error) {
 	h.handleResponse(ctx, resp)
 }
 
+// getErrorInfo returns the HTTPError of the matched router,
+// for not found routes the HTTPError of the nearest group of the path is used
+func (h *handlerServer) getErrorInfo(ctx *Context) *errorInfo {
+	if ctx.path != nil {
+		return h.handle.getErrorInfo(ctx.path)
+	}
+	if group, ok := h.getGroup(ctx, func(group returnObjGroup) bool {
+		return group.errorInfo != nil
+	}); ok {
+		return group.errorInfo
+	}
+	return h.handle.errorMap[ctx.ChildPath]
+}
+
+// getGroup returns the nearest group of the path of the request in the child, which is accepted by the fn
+func (h *handlerServer) getGroup(ctx *Context, fn func(group returnObjGroup) bool) (returnObjGroup, bool) {
+	path := ctx.Request.URL.Path
+	for {
+		if group, ok := h.handle.groupMap[path]; ok && group.childPath == ctx.ChildPath && fn(group) {
+			return group, true
+		}
+		index := strings.LastIndex(path, "/")
+		if index == -1 {
+			return returnObjGroup{}, false
+		}
+		path = path[:index]
+	}
+}
+
 func (h *handlerServer) execRouter(ctx *Context) {
 	path := ctx.path
 	if path.handle != nil {
@@ -186,22 +268,25 @@ func (h *handlerServer) execRouter(ctx *Context) {
 		return
 	}
 	var err error
-	var inputs []reflect.Value
-	lastInputIdx := 0
+	plan := path.bind
+	offset := 0
+	if plan.receiver.IsValid() {
+		offset = 1
+	}
+	// the receiver, the *goapi.Context and the input at most
+	var buf [3]reflect.Value
+	inputs := buf[:len(path.inTypes)+offset]
+	if offset == 1 {
+		inputs[0] = plan.receiver
+	}
 	var ctxVal reflect.Value
-	if len(path.inTypes) == 2 {
-		inputs = make([]reflect.Value, 2)
+	if len(path.inTypes) == 2 || path.existsCtx {
 		ctxVal = reflect.ValueOf(ctx)
-		inputs[0] = ctxVal
-		lastInputIdx = 1
-	} else {
-		inputs = make([]reflect.Value, 1)
-		lastInputIdx = 0
 	}
-	if path.existsCtx && !ctxVal.IsValid() {
-		ctxVal = reflect.ValueOf(ctx)
+	if len(path.inTypes) == 2 {
+		inputs[offset] = ctxVal
 	}
-	inputs[lastInputIdx], err = h.handleInParamToValue(ctx, ctxVal, path.inTypes[lastInputIdx], path.inParams)
+	inputs[len(inputs)-1], err = plan.bind(h, ctx, ctxVal)
 	if err != nil {
 		h.handleError(ctx, getHTTPError(err, validErrorCode))
 		return
@@ -210,7 +295,7 @@ func (h *handlerServer) execRouter(ctx *Context) {
 	if ctx.isRedirect {
 		return
 	}
-	rs := path.value.Call(inputs)
+	rs := plan.call(path.value, inputs)
 	// internal jump judgment of the execution method
 	if ctx.isRedirect {
 		return
@@ -301,335 +386,6 @@ func (h *handlerServer) copyReader(w ResponseWriter, r io.ReadCloser) error {
 	}
 }
 
-func (h *handlerServer) handleInParamToValue(ctx *Context, ctxVal reflect.Value, inType reflect.Type, ins []*inParam) (value reflect.Value, err error) {
-	value = reflect.New(inType).Elem()
-	for value.Kind() == reflect.Ptr {
-		initPtr(value)
-		value = value.Elem()
-	}
-	var formType MediaType
-	for _, field := range ins {
-		if field.inType == inTypeFile {
-			formType = formMultipart
-		} else if field.inType == inTypeForm && formType != formMultipart {
-			formType = formUrlencoded
-		}
-	}
-	switch formType {
-	case formUrlencoded:
-		if err = ctx.Request.ParseForm(); err != nil {
-			return
-		}
-	case formMultipart:
-		if err = ctx.Request.ParseMultipartForm(32 << 20); err != nil {
-			return
-		}
-	}
-	for _, in := range ins {
-		inValue := h.getParamValue(value, in.deeps)
-		switch in.inType {
-		case inTypePath:
-			if val, ok := ctx.Params.Get(in.values[0].name); ok {
-				if err = h.handleParamByString(ctx, inValue, in.field, val); err != nil {
-					return
-				}
-			}
-		case inTypeQuery:
-			err = h.handleParamByStringSlice(ctx, inValue, in.field, ctx.Query()[in.values[0].name])
-			if err != nil {
-				return
-			}
-		case inTypeHeader:
-			if err = h.handleParamByString(ctx, inValue, in.field, ctx.Request.Header.Get(in.values[0].name)); err != nil {
-				return
-			}
-		case inTypeCookie:
-			cookie, _ := ctx.Request.Cookie(in.values[0].name)
-			if in.field._type.ConvertibleTo(typeCookie) {
-				if err = h.handleParamByCookie(ctx, inValue, in.field, cookie); err != nil {
-					return
-				}
-				continue
-			}
-			val := ""
-			if cookie != nil {
-				val = cookie.Value
-			}
-			if err = h.handleParamByString(ctx, inValue, in.field, val); err != nil {
-				return
-			}
-		case inTypeForm:
-			val := ""
-			switch formType {
-			case formUrlencoded:
-				val = ctx.Request.Form.Get(in.values[0].name)
-			case formMultipart:
-				if ctx.Request.MultipartForm != nil && ctx.Request.MultipartForm.Value[in.values[0].name] != nil {
-					val = ctx.Request.MultipartForm.Value[in.values[0].name][0]
-				}
-			}
-			if err = h.handleParamByString(ctx, inValue, in.field, val); err != nil {
-				return
-			}
-		case inTypeFile:
-			var files []*multipart.FileHeader
-			if ctx.Request.MultipartForm != nil {
-				files = ctx.Request.MultipartForm.File[in.values[0].name]
-			}
-			if err = h.handleParamByFields(ctx, inValue, in.field, files); err != nil {
-				return
-			}
-		case inTypeBody:
-			mediaType := h.getRequestMediaType(ctx)
-			if !h.isBodyMediaTypeAllowed(mediaType, in.values) {
-				err = NewHTTPError(http.StatusUnsupportedMediaType, http.StatusText(http.StatusUnsupportedMediaType))
-				return
-			}
-			err = h.setBody(inValue, ctx.Request.Body, mediaType)
-			if err != nil {
-				return
-			}
-			if mediaType.IsStream() {
-				continue
-			}
-			err = h.validParamField(ctx, inValue, in.field, mediaType)
-			if err != nil {
-				return
-			}
-		case inTypeSecurityHTTPBearer:
-			initPtr(inValue)
-			authorization := ctx.Request.Header.Get("Authorization")
-			authType, token, _ := strings.Cut(authorization, " ")
-			if toFirstUpper(authType) != "Bearer" {
-				token = ""
-			}
-			inValueAny := inValue.Interface()
-			valOmitempty := false
-			if fn, ok := inValueAny.(SecurityOmitempty); ok {
-				valOmitempty = fn.Omitempty()
-			}
-			if !valOmitempty && token == "" {
-				err = NewHTTPError(authErrorCode, ctx.lang().NotAuthenticated())
-				return
-			}
-			security := inValueAny.(HTTPBearer)
-			if err = security.HTTPBearer(token); err != nil {
-				return
-			}
-		case inTypeSecurityHTTPBearerJWT:
-			initPtr(inValue)
-			authorization := ctx.Request.Header.Get("Authorization")
-			authType, token, _ := strings.Cut(authorization, " ")
-			if toFirstUpper(authType) != "Bearer" {
-				token = ""
-			}
-			inValueAny := inValue.Interface()
-			security := inValueAny.(HTTPBearerJWT)
-			valOmitempty := false
-			if fn, ok := inValueAny.(SecurityOmitempty); ok {
-				valOmitempty = fn.Omitempty()
-			}
-			if token == "" {
-				if valOmitempty {
-					if err = security.HTTPBearerJWT(nil); err != nil {
-						return
-					}
-					continue
-				}
-				err = NewHTTPError(authErrorCode, ctx.lang().NotAuthenticated())
-				return
-			}
-			jwt := &JWT{}
-			if err = decryptJWT(jwt, token, security); err != nil {
-				err = NewHTTPError(authErrorCode, ctx.lang().JwtTranslate(err.Error()))
-				return
-			}
-			if err = security.HTTPBearerJWT(jwt); err != nil {
-				return
-			}
-		case inTypeSecurityHTTPBasic:
-			initPtr(inValue)
-			username, password, _ := ctx.Request.BasicAuth()
-			inValueAny := inValue.Interface()
-			valOmitempty := false
-			if fn, ok := inValueAny.(SecurityOmitempty); ok {
-				valOmitempty = fn.Omitempty()
-			}
-			if !valOmitempty && username == "" {
-				err = NewHTTPError(authErrorCode, ctx.lang().NotAuthenticated())
-				return
-			}
-			security := inValueAny.(HTTPBasic)
-			if err = security.HTTPBasic(username, password); err != nil {
-				return
-			}
-		case inTypeSecurityApiKey:
-			initPtr(inValue)
-			security := inValue.Interface().(ApiKey)
-			if err = security.ApiKey(); err != nil {
-				return
-			}
-		case inTypeCtx:
-			h.handleParamByCtx(ctxVal, inValue)
-		}
-	}
-	return
-}
-
-func (h *handlerServer) validParamField(ctx *Context, value reflect.Value, field *paramField, mediaType MediaType) (err error) {
-	name := field.names.getFieldName(mediaType)
-	desc := h.getDesc(name.name, field)
-	if !field.anonymous {
-		if value.Kind() != reflect.Ptr {
-			if value.IsZero() {
-				if name.required {
-					return errors.New(ctx.lang().Required(desc))
-				}
-				if defaultSet(value, field.meta._default) {
-					return h.validParamField(ctx, value, field, mediaType)
-				}
-				return
-			}
-		} else {
-			for value.Kind() == reflect.Ptr {
-				if value.IsNil() {
-					if name.required {
-						return errors.New(ctx.lang().Required(desc))
-					}
-					if defaultSet(value, field.meta._default) {
-						return h.validParamField(ctx, value, field, mediaType)
-					}
-					return
-				}
-				if _, ok := getTypeByCovertInterface[TextInterface](value); ok {
-					break
-				}
-				value = value.Elem()
-			}
-		}
-	}
-	realValue := value
-	for value.Kind() == reflect.Ptr {
-		initPtr(value)
-		realValue = value
-		value = value.Elem()
-	}
-	if err = h.handleValidate(field, realValue); err != nil {
-		return
-	}
-	switch field.kind {
-	case reflect.Struct:
-		fields := field.fields
-		if field.pkgName != "" {
-			if sInfo := h.handle.structs[field.pkgName]; sInfo != nil {
-				fields = sInfo.fields
-			}
-		}
-		for _, childField := range fields {
-			if err = h.validParamField(ctx, value.Field(childField.index), childField, mediaType); err != nil {
-				return
-			}
-		}
-	case reflect.Slice, reflect.Array:
-		if field.meta.max != nil && uint64(value.Len()) > *field.meta.max {
-			return errors.New(ctx.lang().Max(desc, *field.meta.max))
-		}
-		if uint64(value.Len()) < field.meta.min {
-			return errors.New(ctx.lang().Min(desc, field.meta.min))
-		}
-		if field.meta.unique {
-			m := map[any]struct{}{}
-			for i := 0; i < value.Len(); i++ {
-				itemVal := value.Index(i)
-				if !itemVal.Comparable() {
-					continue
-				}
-				item := itemVal.Interface()
-				if _, ok := m[item]; ok {
-					return errors.New(ctx.lang().Unique(desc))
-				}
-				m[item] = struct{}{}
-			}
-		}
-		for i := 0; i < value.Len(); i++ {
-			if err = h.validParamField(ctx, value.Index(i), field.fields[0], mediaType); err != nil {
-				return
-			}
-		}
-	case reflect.Map:
-		if field.meta.max != nil && uint64(value.Len()) > *field.meta.max {
-			return errors.New(ctx.lang().Max(desc, *field.meta.max))
-		}
-		if uint64(value.Len()) < field.meta.min {
-			return errors.New(ctx.lang().Min(desc, field.meta.min))
-		}
-		for _, key := range value.MapKeys() {
-			if err = h.validParamField(ctx, key, field.fields[0], mediaType); err != nil {
-				return
-			}
-			if err = h.validParamField(ctx, value.MapIndex(key), field.fields[1], mediaType); err != nil {
-				return
-			}
-		}
-	case reflect.String:
-		valStr := ""
-		var enum []any
-		if field.meta.enum != nil {
-			enum = make([]any, len(field.meta.enum))
-			copy(enum, field.meta.enum)
-		}
-		if field.isTextType {
-			if fn, ok := getFnByCovertInterface[encoding.TextMarshaler](value); ok {
-				var txt []byte
-				if txt, err = fn.MarshalText(); err == nil {
-					valStr = string(txt)
-				}
-			}
-			for k, v := range enum {
-				if fn, ok := getFnByCovertInterface[encoding.TextMarshaler](v); ok {
-					var txt []byte
-					if txt, err = fn.MarshalText(); err == nil {
-						enum[k] = string(txt)
-					}
-				}
-			}
-		} else {
-			valStr = value.String()
-		}
-		if field.meta.max != nil && uint64(len(valStr)) > *field.meta.max {
-			return errors.New(ctx.lang().Max(desc, *field.meta.max))
-		}
-		if uint64(len(valStr)) < field.meta.min {
-			return errors.New(ctx.lang().Min(desc, field.meta.min))
-		}
-		if field.meta.regexp != "" {
-			if re := h.getCompiledRegexp(field.meta.regexp); re != nil && !re.MatchString(valStr) {
-				return errors.New(ctx.lang().Regexp(desc, field.meta.regexp))
-			}
-		}
-		if enum != nil && !inArrayAny(any(valStr), enum) {
-			return errors.New(ctx.lang().Enum(desc, enum))
-		}
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		vFloat := float64(value.Int())
-		if err = h.validFloat64(ctx, vFloat, desc, field); err != nil {
-			return
-		}
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		vFloat := float64(value.Uint())
-		if err = h.validFloat64(ctx, vFloat, desc, field); err != nil {
-			return
-		}
-	case reflect.Float32, reflect.Float64:
-		vFloat := value.Float()
-		if err = h.validFloat64(ctx, vFloat, desc, field); err != nil {
-			return
-		}
-	default:
-	}
-	return
-}
-
 // getCompiledRegexp Return the compiled regular expression and use caching to avoid repeated compilation for each request
 func (h *handlerServer) getCompiledRegexp(expr string) *regexp.Regexp {
 	if expr == "" {
@@ -1003,19 +759,6 @@ func (h *handlerServer) removeMorPtrValue(value reflect.Value) reflect.Value {
 	return value
 }
 
-func (h *handlerServer) getParamValue(value reflect.Value, deeps []int) reflect.Value {
-	for len(deeps) > 0 {
-		index := deeps[0]
-		deeps = deeps[1:]
-		for value.Kind() == reflect.Ptr {
-			initPtr(value)
-			value = value.Elem()
-		}
-		value = value.Field(index)
-	}
-	return value
-}
-
 func (h *handlerServer) setBody(value reflect.Value, reader io.ReadCloser, mediaType MediaType) (err error) {
 	if reader == nil {
 		return nil
@@ -1076,40 +819,46 @@ func (h *handlerServer) notFind(ctx *Context) {
 	ctx.handlers = append(ctx.handlers, func(ctx *Context) {
 		child := h.handle.childMap[ctx.ChildPath]
 		if child.handleMethodNotAllowed {
-			allowed := make([]string, 0, len(h.trees)-1)
-			for _, tree := range h.trees {
-				if tree.method == ctx.Request.Method {
-					continue
-				}
-				params := make(Params, 0, h.maxParams)
-				skippedNodes := make([]skippedNode, 0, h.maxSkippedNodes)
-				if val := tree.root.getValue(ctx.Request.URL.Path, &params, &skippedNodes); val.handler != nil {
-					allowed = append(allowed, tree.method)
-				}
-			}
-			if len(allowed) > 0 {
+			if allowed := h.allowedMethods(ctx, child); len(allowed) > 0 {
 				ctx.Writer.Header().Set("Allow", strings.Join(allowed, ", "))
-				child.noMethod(ctx)
+				noMethod := child.noMethod
+				if group, ok := h.getGroup(ctx, func(group returnObjGroup) bool {
+					return group.noMethod != nil
+				}); ok {
+					noMethod = group.noMethod
+				}
+				noMethod(ctx)
 				return
 			}
 		}
-		child.noRoute(ctx)
+		noRoute := child.noRoute
+		if group, ok := h.getGroup(ctx, func(group returnObjGroup) bool {
+			return group.noRoute != nil
+		}); ok {
+			noRoute = group.noRoute
+		}
+		noRoute(ctx)
 	})
 	ctx.Next()
 }
 
 func (h *handlerServer) redirect(ctx *Context) {
+	code := http.StatusMovedPermanently
+	if ctx.Request.Method != http.MethodGet {
+		code = http.StatusTemporaryRedirect
+	}
+	h.redirectTo(ctx, h.handleTsrPath(ctx.Request.URL.Path), code)
+}
+
+// redirectTo redirects to the path with the query of the request, the group middlewares are executed before
+func (h *handlerServer) redirectTo(ctx *Context, path string, code int) {
 	ctx.handlers = h.getMiddlewares(ctx.Request.URL.Path)
 	ctx.handlers = append(ctx.handlers, func(ctx *Context) {
-		tsrPath := h.handleTsrPath(ctx.Request.URL.Path)
+		location := path
 		if ctx.Request.URL.RawQuery != "" {
-			tsrPath += "?" + ctx.Request.URL.RawQuery
-		}
-		code := http.StatusMovedPermanently
-		if ctx.Request.Method != http.MethodGet {
-			code = http.StatusTemporaryRedirect
+			location += "?" + ctx.Request.URL.RawQuery
 		}
-		ctx.Redirect(code, tsrPath)
+		ctx.Redirect(code, location)
 	})
 	ctx.Next()
 }
@@ -1121,12 +870,17 @@ func (h *handlerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx.writermem.reset(w)
 	ctx.reset()
 	ctx.Request = r
+	ctx.server = h
 	if ctx.handleError == nil {
 		ctx.handleError = h.handleError
 	}
 	h.generateRequestID(ctx)
 	ctx.langInfo = h.parseAcceptLanguage(ctx, h.handle.langList)
-	h.handleHTTPRequest(ctx)
+	// the cleanups are executed even if a panic escapes, the context is not reused then
+	func() {
+		defer ctx.runCleanups()
+		h.handleHTTPRequest(ctx)
+	}()
 	h.pool.Put(ctx)
 }
 
@@ -1168,34 +922,198 @@ func (h *handlerServer) handleLogger(ctx *Context) {
 	}
 }
 
-func (h *handlerServer) handleHTTPRequest(ctx *Context) {
-	root := h.trees.get(ctx.Request.Method)
+// handleHostRequest handles the request by the routers of the matched hosts, returns false if no router matches
+func (h *handlerServer) handleHostRequest(ctx *Context, method string) bool {
+	host := requestHost(ctx.Request.Host)
+	for _, tree := range h.hostTrees {
+		root := tree.trees.get(method)
+		if root == nil {
+			continue
+		}
+		values, ok := tree.host.match(host)
+		if !ok {
+			continue
+		}
+		value := root.getValue(ctx.Request.URL.Path, ctx.Params, ctx.skippedNodes)
+		if value.handler != nil {
+			for i, name := range tree.host.names {
+				*ctx.Params = append(*ctx.Params, Param{Key: name, Value: values[i]})
+			}
+			ctx.fullPath = value.fullPath
+			value.handler(ctx)
+			return true
+		}
+		*ctx.Params = (*ctx.Params)[:0]
+		*ctx.skippedNodes = (*ctx.skippedNodes)[:0]
+	}
+	return false
+}
+
+// handleMethodRequest handles the request by the routers of the method, returns false if no router matches
+func (h *handlerServer) handleMethodRequest(ctx *Context, method string) (value nodeValue, ok bool) {
+	if len(h.hostTrees) > 0 && h.handleHostRequest(ctx, method) {
+		return value, true
+	}
+	root := h.trees.get(method)
 	if root == nil {
-		ctx.ChildPath = h.getChildPath(ctx.Request.URL.Path)
-		h.handleLogger(ctx)
-		h.notFind(ctx)
 		return
 	}
-	value := root.getValue(ctx.Request.URL.Path, ctx.Params, ctx.skippedNodes)
+	value = root.getValue(ctx.Request.URL.Path, ctx.Params, ctx.skippedNodes)
 	if value.handler != nil {
 		ctx.fullPath = value.fullPath
 		value.handler(ctx)
-		return
+		return value, true
 	}
 	*ctx.Params = (*ctx.Params)[:0]
 	*ctx.skippedNodes = (*ctx.skippedNodes)[:0]
-	ctx.ChildPath = h.getChildPath(ctx.Request.URL.Path)
+	return
+}
+
+func (h *handlerServer) handleHTTPRequest(ctx *Context) {
+	value, ok := h.handleRouterRequest(ctx)
+	if ok {
+		return
+	}
+	child := h.handle.childMap[ctx.ChildPath]
 	h.handleLogger(ctx)
-	if value.tsr {
-		child := h.handle.childMap[ctx.ChildPath]
-		if child.redirectTrailingSlash {
-			h.redirect(ctx)
+	if value.tsr && child.redirectTrailingSlash {
+		h.redirect(ctx)
+		return
+	}
+	if child.redirectFixedPath || child.caseInsensitive {
+		if fixedPath, found := h.fixedPath(ctx, child); found {
+			if child.redirectFixedPath {
+				code := http.StatusMovedPermanently
+				if ctx.Request.Method != http.MethodGet {
+					code = http.StatusPermanentRedirect
+				}
+				h.redirectTo(ctx, fixedPath, code)
+				return
+			}
+			// serve the fixed path directly
+			reqPath, rawPath := ctx.Request.URL.Path, ctx.Request.URL.RawPath
+			ctx.Request.URL.Path, ctx.Request.URL.RawPath = fixedPath, ""
+			if _, ok = h.handleRouterRequest(ctx); ok {
+				return
+			}
+			ctx.Request.URL.Path, ctx.Request.URL.RawPath = reqPath, rawPath
+			ctx.ChildPath = h.getChildPath(reqPath)
+		}
+	}
+	if ctx.Request.Method == http.MethodOptions && child.handleOptions {
+		if allowed := h.allowedMethods(ctx, child); len(allowed) > 0 {
+			h.options(ctx, allowed)
 			return
 		}
 	}
 	h.notFind(ctx)
 }
 
+// handleRouterRequest handles the request by the routers, the GET routers answer HEAD if HandleHead is enabled.
+// If no router matches, the ChildPath of the context is set
+func (h *handlerServer) handleRouterRequest(ctx *Context) (value nodeValue, ok bool) {
+	if value, ok = h.handleMethodRequest(ctx, ctx.Request.Method); ok {
+		return
+	}
+	ctx.ChildPath = h.getChildPath(ctx.Request.URL.Path)
+	child := h.handle.childMap[ctx.ChildPath]
+	if ctx.Request.Method == http.MethodHead && child.handleHead {
+		// the GET router is executed, and the body is discarded
+		w := ctx.writermem.ResponseWriter
+		ctx.writermem.ResponseWriter = &headResponseWriter{ResponseWriter: w}
+		if _, ok = h.handleMethodRequest(ctx, http.MethodGet); ok {
+			return
+		}
+		ctx.writermem.ResponseWriter = w
+	}
+	return
+}
+
+// fixedPath returns the cleaned path of the request that matches a router,
+// the static parts are matched case-insensitively if CaseInsensitive is enabled
+func (h *handlerServer) fixedPath(ctx *Context, child returnObjChild) (string, bool) {
+	reqPath := ctx.Request.URL.Path
+	cleaned := cleanPath(reqPath)
+	methods := []string{ctx.Request.Method}
+	if ctx.Request.Method == http.MethodHead && child.handleHead {
+		methods = append(methods, http.MethodGet)
+	}
+	host := requestHost(ctx.Request.Host)
+	params := make(Params, 0, h.maxParams)
+	skippedNodes := make([]skippedNode, 0, h.maxSkippedNodes)
+	lookup := func(root *node) (string, bool) {
+		if root == nil {
+			return "", false
+		}
+		if child.caseInsensitive {
+			return root.findCaseInsensitivePath(cleaned)
+		}
+		params = params[:0]
+		skippedNodes = skippedNodes[:0]
+		return cleaned, root.getValue(cleaned, &params, &skippedNodes).handler != nil
+	}
+	for _, method := range methods {
+		for _, tree := range h.hostTrees {
+			if _, ok := tree.host.match(host); !ok {
+				continue
+			}
+			if p, ok := lookup(tree.trees.get(method)); ok && p != reqPath {
+				return p, true
+			}
+		}
+		if p, ok := lookup(h.trees.get(method)); ok && p != reqPath {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// allowedMethods returns the methods of the routers that match the path of the request except the method of the request
+func (h *handlerServer) allowedMethods(ctx *Context, child returnObjChild) (allowed []string) {
+	host := requestHost(ctx.Request.Host)
+	params := make(Params, 0, h.maxParams)
+	skippedNodes := make([]skippedNode, 0, h.maxSkippedNodes)
+	match := func(trees methodTrees) {
+		for _, tree := range trees {
+			if tree.method == ctx.Request.Method || inArray(tree.method, allowed) {
+				continue
+			}
+			params = params[:0]
+			skippedNodes = skippedNodes[:0]
+			if val := tree.root.getValue(ctx.Request.URL.Path, &params, &skippedNodes); val.handler != nil {
+				allowed = append(allowed, tree.method)
+			}
+		}
+	}
+	for _, tree := range h.hostTrees {
+		if _, ok := tree.host.match(host); ok {
+			match(tree.trees)
+		}
+	}
+	match(h.trees)
+	if len(allowed) == 0 {
+		return
+	}
+	if child.handleHead && inArray(http.MethodGet, allowed) && !inArray(http.MethodHead, allowed) &&
+		ctx.Request.Method != http.MethodHead {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if child.handleOptions && !inArray(http.MethodOptions, allowed) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	return
+}
+
+// options answers the OPTIONS request with the 'Allow' header, the group middlewares are executed before
+func (h *handlerServer) options(ctx *Context, allowed []string) {
+	ctx.handlers = h.getMiddlewares(ctx.Request.URL.Path)
+	ctx.handlers = append(ctx.handlers, func(ctx *Context) {
+		ctx.Writer.Header().Set("Allow", strings.Join(allowed, ", "))
+		ctx.Writer.WriteHeader(http.StatusNoContent)
+	})
+	ctx.Next()
+}
+
 func (h *handlerServer) handleTsrPath(path string) string {
 	if path[len(path)-1] == '/' {
 		path = path[:len(path)-1]
@@ -0,0 +1,70 @@
+package goapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type headOptionsTestRouter struct{}
+
+func (r *headOptionsTestRouter) List(input struct {
+	router Router `paths:"/items" methods:"GET"`
+}) string {
+	return "items"
+}
+
+func (r *headOptionsTestRouter) Create(input struct {
+	router Router `paths:"/items" methods:"POST"`
+}) string {
+	return "created"
+}
+
+func TestHandleHeadAndOptions(t *testing.T) {
+	newHandler := func(enable bool) http.Handler {
+		api := New(true)
+		api.SetLogger(nil)
+		api.HandleHead = enable
+		api.HandleOptions = enable
+		api.AddMiddleware(func(ctx *Context) {
+			ctx.Writer.Header().Set("X-Middleware", "1")
+			ctx.Next()
+		})
+		api.IncludeRouter(&headOptionsTestRouter{}, "", false)
+		handler, err := api.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return handler
+	}
+	handler := newHandler(true)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/items", nil))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 || w.Header().Get("X-Middleware") != "1" {
+		t.Fatalf("HEAD: got %v %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/items", nil))
+	allowed := strings.Split(w.Header().Get("Allow"), ", ")
+	sort.Strings(allowed)
+	if w.Code != http.StatusNoContent || strings.Join(allowed, ",") != "GET,HEAD,OPTIONS,POST" ||
+		w.Header().Get("X-Middleware") != "1" {
+		t.Fatalf("OPTIONS: got %v, Allow %q", w.Code, w.Header().Get("Allow"))
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/none", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("OPTIONS of not found: got %v", w.Code)
+	}
+
+	handler = newHandler(false)
+	for _, method := range []string{http.MethodHead, http.MethodOptions} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/items", nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("%v of disabled: got %v", method, w.Code)
+		}
+	}
+}
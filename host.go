@@ -0,0 +1,111 @@
+package goapi
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hostPattern matches the host of the request without the port, such as 'api.example.com' or '{tenant}.example.com'
+type hostPattern struct {
+	pattern string
+
+	// the names of the wildcards in order
+	names []string
+
+	// the host with the constraints of the wildcards removed, such as '{tenant}.example.com'
+	docsHost string
+
+	// nil means the host is exact
+	regexp *regexp.Regexp
+
+	// the submatch indexes of the wildcards in regexp
+	indexes []int
+}
+
+func newHostPattern(pattern string) (h *hostPattern, err error) {
+	if pattern == "" {
+		return nil, errors.New("the host cannot be empty")
+	}
+	h = &hostPattern{pattern: pattern}
+	n := &node{}
+	var expr, docsHost strings.Builder
+	expr.WriteString("(?i)^")
+	for rest := pattern; ; {
+		wildcard, i, valid := n.findWildcard(rest)
+		if !valid {
+			return nil, errors.New("wildcards must be between ‘{’ and ‘}’, has '" + wildcard + "' in host '" + pattern + "'")
+		}
+		if i < 0 {
+			expr.WriteString(regexp.QuoteMeta(rest))
+			docsHost.WriteString(rest)
+			break
+		}
+		if len(wildcard) < 3 {
+			return nil, errors.New("wildcards must be named with a non-empty name, has '" + wildcard + "' in host '" +
+				pattern + "'")
+		}
+		name, nType := n.parseWildcard(wildcard)
+		if nType == catchAll {
+			return nil, errors.New("'catch-all' wildcard is not supported in host '" + pattern + "'")
+		}
+		constraint, constraintErr := n.parseConstraint(wildcard)
+		if constraintErr != nil {
+			return nil, errors.New(constraintErr.Error() + " in host '" + pattern + "'")
+		}
+		expr.WriteString(regexp.QuoteMeta(rest[:i]))
+		docsHost.WriteString(rest[:i] + "{" + name + "}")
+		group := "(?P<h" + strconv.Itoa(len(h.names)) + ">"
+		if constraint != nil {
+			// the pattern of the constraint is anchored by '^' and '$'
+			expr.WriteString(group + constraint.pattern[1:len(constraint.pattern)-1] + ")")
+		} else {
+			expr.WriteString(group + `[^.]+)`)
+		}
+		h.names = append(h.names, name)
+		rest = rest[i+len(wildcard):]
+	}
+	expr.WriteString("$")
+	h.docsHost = docsHost.String()
+	if len(h.names) > 0 {
+		if h.regexp, err = regexp.Compile(expr.String()); err != nil {
+			return nil, errors.New("the host '" + pattern + "' is invalid: " + err.Error())
+		}
+		for i := range h.names {
+			h.indexes = append(h.indexes, h.regexp.SubexpIndex("h"+strconv.Itoa(i)))
+		}
+	}
+	return
+}
+
+// match returns the values of the wildcards and a boolean true if the host matches
+func (h *hostPattern) match(host string) (values []string, ok bool) {
+	if h.regexp == nil {
+		return nil, strings.EqualFold(h.pattern, host)
+	}
+	matches := h.regexp.FindStringSubmatch(host)
+	if matches == nil {
+		return nil, false
+	}
+	values = make([]string, len(h.indexes))
+	for i, index := range h.indexes {
+		values[i] = matches[index]
+	}
+	return values, true
+}
+
+// requestHost returns the host of the request without the port
+func requestHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// hostTree is the routers of a host, see RouterGroup.Host
+type hostTree struct {
+	host  *hostPattern
+	trees methodTrees
+}
@@ -0,0 +1,130 @@
+package goapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type hostTestRouter struct{}
+
+func (h *hostTestRouter) Tenant(ctx *Context, input struct {
+	router Router `paths:"/info" methods:"GET"`
+	Tenant string `host:"tenant" desc:"the tenant" example:"acme"`
+}) string {
+	return "tenant:" + input.Tenant + "," + ctx.Params.ByName("tenant")
+}
+
+type hostAPITestRouter struct{}
+
+func (h *hostAPITestRouter) Info(input struct {
+	router Router `paths:"/info" methods:"GET"`
+}) string {
+	return "api"
+}
+
+func TestNewHostPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		values  []string
+		ok      bool
+	}{
+		{pattern: "api.example.com", host: "API.example.com", ok: true},
+		{pattern: "api.example.com", host: "www.example.com"},
+		{pattern: "{tenant}.example.com", host: "acme.example.com", values: []string{"acme"}, ok: true},
+		{pattern: "{tenant}.example.com", host: "a.b.example.com"},
+		{pattern: "{tenant:[a-z]+}.{region}.example.com", host: "acme.eu.example.com", values: []string{"acme", "eu"}, ok: true},
+		{pattern: "{tenant:[a-z]+}.example.com", host: "acme1.example.com"},
+		{pattern: "{id:(a|b)c}.{region}.example.com", host: "bc.eu.example.com", values: []string{"bc", "eu"}, ok: true},
+	}
+	for _, tt := range tests {
+		pattern, err := newHostPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.pattern, err)
+		}
+		values, ok := pattern.match(tt.host)
+		if ok != tt.ok || strings.Join(values, ",") != strings.Join(tt.values, ",") {
+			t.Errorf("%v match %v: got %v %v want %v %v", tt.pattern, tt.host, values, ok, tt.values, tt.ok)
+		}
+	}
+	for _, pattern := range []string{"", "{path:*}.example.com", "{}.example.com", "{id:[a-}.example.com"} {
+		if _, err := newHostPattern(pattern); err == nil {
+			t.Errorf("%q should be invalid", pattern)
+		}
+	}
+}
+
+func TestHostRouting(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", true)
+	apiGroup := api.Group("", true)
+	apiGroup.Host("api.example.com")
+	apiGroup.IncludeRouter(&hostAPITestRouter{}, "/v1", true)
+	tenant := api.Child("/tenant", "/tenant/docs")
+	tenant.Host("{tenant}.example.com")
+	tenant.IncludeRouter(&hostTestRouter{}, "", true)
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		host   string
+		path   string
+		status int
+		body   string
+	}{
+		{host: "api.example.com:8080", path: "/v1/info", status: http.StatusOK, body: `"api"`},
+		{host: "acme.example.com", path: "/tenant/info", status: http.StatusOK, body: `"tenant:acme,acme"`},
+		{host: "api.example.com", path: "/tenant/info", status: http.StatusOK, body: `"tenant:api,api"`},
+		{host: "acme.example.com", path: "/v1/info", status: http.StatusNotFound},
+		{host: "localhost", path: "/tenant/info", status: http.StatusNotFound},
+		{host: "acme.example.com", path: "/ping", status: http.StatusOK, body: `"pong"`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		r.Host = tt.host
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.status || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%v%v: got %v %q want %v %q", tt.host, tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/tenant/docs/openapi.json", nil))
+	doc := w.Body.String()
+	for _, want := range []string{`"url":"{scheme}://{tenant}.example.com"`, `"tenant":{"default":"acme","description":"the tenant"}`} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("openapi.json should contain %v, got %v", want, doc)
+		}
+	}
+}
+
+func TestHostInvalid(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	group := api.Group("", false)
+	group.Host("{path:*}.example.com")
+	group.IncludeRouter(&hostAPITestRouter{}, "", false)
+	if _, err := api.Build(); err == nil {
+		t.Fatal("Build should fail for an invalid host")
+	}
+}
+
+func TestHostParameterWithoutWildcard(t *testing.T) {
+	for _, pattern := range []string{"{region}.example.com", ""} {
+		api := New(false)
+		api.SetLogger(nil)
+		group := api.Group("/tenant", false)
+		if pattern != "" {
+			group.Host(pattern)
+		}
+		group.IncludeRouter(&hostTestRouter{}, "", false)
+		_, err := api.Build()
+		if err == nil || !strings.Contains(err.Error(), "the host parameter 'tenant' has no wildcard '{tenant}'") {
+			t.Errorf("%q: Build error: got %v", pattern, err)
+		}
+	}
+}
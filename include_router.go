@@ -18,7 +18,8 @@ type includeRouter struct {
 	isDocs      bool
 	docsPath    string
 	childPath   string
-	middlewares []HandleFunc
+	middlewares []middlewareInfo
+	providers   *providers
 }
 
 func (i *includeRouter) returnObj() (obj returnObjResult, err error) {
@@ -42,10 +43,14 @@ func (i *includeRouter) returnObj() (obj returnObjResult, err error) {
 		}
 	}
 	value := reflect.ValueOf(i.router)
+	typed, _ := i.router.(*typedRouter)
+	if typed != nil {
+		value = typed.handler
+	}
 	var pInfo *pathInfo
 	if value.Kind() == reflect.Func {
 		funcPos := runtime.FuncForPC(value.Pointer()).Name()
-		pInfo, err = i.handleRouter(value)
+		pInfo, err = i.handleRouter(value, typed)
 		if err != nil {
 			err = fmt.Errorf("%v, pos: %v", err, funcPos)
 			return
@@ -70,19 +75,22 @@ func (i *includeRouter) returnObj() (obj returnObjResult, err error) {
 		err = fmt.Errorf("router must be a struct, struct pointer or function, pos: %v", pos)
 		return
 	}
+	var errs []error
 	numMethod := value.NumMethod()
 	for j := 0; j < numMethod; j++ {
 		funcPos := fmt.Sprintf("%v.%v", pos, value.Type().Method(j).Name)
 		routerMethod := value.Method(j)
-		pInfo, err = i.handleRouter(routerMethod)
+		pInfo, err = i.handleRouter(routerMethod, nil)
 		if err != nil {
-			err = fmt.Errorf("%v, pos: %v", err, funcPos)
-			return
+			errs = append(errs, fmt.Errorf("%v, pos: %v", err, funcPos))
+			continue
 		}
 		if pInfo == nil {
 			continue
 		}
 		pInfo.pos = funcPos
+		pInfo.receiver = value
+		pInfo.method = value.Type().Method(j).Func
 		for _, v := range pInfo.inParams {
 			for _, mediaType := range v.values.MediaTypes() {
 				if mediaType.Tag() != "" {
@@ -93,10 +101,11 @@ func (i *includeRouter) returnObj() (obj returnObjResult, err error) {
 		pInfo.tags = mergePathTags(pInfo.tags, tagStrs)
 		obj.paths = append(obj.paths, pInfo)
 	}
+	err = newBuildError(errs)
 	return
 }
 
-func (i *includeRouter) handleRouter(routerMethod reflect.Value) (pInfo *pathInfo, err error) {
+func (i *includeRouter) handleRouter(routerMethod reflect.Value, typed *typedRouter) (pInfo *pathInfo, err error) {
 	// handle in param
 	numIn := routerMethod.Type().NumIn()
 	if numIn == 0 {
@@ -130,63 +139,32 @@ func (i *includeRouter) handleRouter(routerMethod reflect.Value) (pInfo *pathInf
 		return
 	}
 	pInfo = &pathInfo{
-		value:       routerMethod,
-		inTypes:     inTypes,
-		middlewares: i.middlewares,
-		isDocs:      i.isDocs,
-		docsPath:    i.docsPath,
-		childPath:   i.childPath,
-		groupPrefix: i.groupPrefix,
+		value:           routerMethod,
+		inTypes:         inTypes,
+		middlewareInfos: i.middlewares,
+		isDocs:          i.isDocs,
+		docsPath:        i.docsPath,
+		childPath:       i.childPath,
+		groupPrefix:     i.groupPrefix,
+	}
+	if typed != nil {
+		if err = i.handleTypedRouter(pInfo, typed); err != nil {
+			return
+		}
 	}
 	numField := inputType.NumField()
 	var in []*inParam
-	allMds := allMethods()
 	for l := 0; l < numField; l++ {
 		field := inputType.Field(l)
 		switch field.Type {
 		case reflect.TypeOf(Router{}):
-			pathStr, pathOk := field.Tag.Lookup(tagPaths)
-			methodStr, methodOk := field.Tag.Lookup(tagMethods)
-			if (i.prefix == "" && pathStr == "") || methodStr == "" || !pathOk || !methodOk {
-				err = fmt.Errorf("the 'goapi.Router' parameter must have tags for 'paths' and 'methods'")
+			if typed != nil {
+				err = fmt.Errorf("the 'goapi.Router' parameter cannot be used with the generic routers such as goapi.Get")
 				return
 			}
-			methods := strings.Split(methodStr, ",")
-			upperMethods := make([]string, len(methods))
-			for k, v := range methods {
-				upperMethods[k] = strings.ToUpper(v)
-			}
-			var noMethods []string
-			for k, v := range upperMethods {
-				if !inArray(v, allMds) {
-					noMethods = append(noMethods, methods[k])
-				}
-			}
-			if len(noMethods) > 0 {
-				err = fmt.Errorf("methods '%v' does not exist, must be in '%v'",
-					strings.Join(noMethods, "', '"), strings.Join(allMds, "', '"))
+			if err = i.handleRouterTag(pInfo, field.Tag); err != nil {
 				return
 			}
-			paths := strings.Split(pathStr, ",")
-			for k, v := range paths {
-				paths[k] = pathJoin(i.prefix, v)
-			}
-			deprecated := false
-			deprecatedStr := field.Tag.Get("deprecated")
-			if deprecatedStr != "" {
-				if deprecated, err = strconv.ParseBool(field.Tag.Get("deprecated")); err != nil {
-					return
-				}
-			}
-			pInfo.paths = paths
-			pInfo.methods = upperMethods
-			pInfo.summary = field.Tag.Get(tagSummary)
-			pInfo.desc = field.Tag.Get(tagDesc)
-			pInfo.deprecated = deprecated
-			tag := field.Tag.Get(tagTags)
-			if tag != "" {
-				pInfo.tags = strings.Split(tag, ",")
-			}
 		default:
 			if in, err = i.parseIn(field, []int{l}, ""); err != nil {
 				return
@@ -194,6 +172,7 @@ func (i *includeRouter) handleRouter(routerMethod reflect.Value) (pInfo *pathInf
 			pInfo.inParams = append(pInfo.inParams, in...)
 		}
 	}
+	pInfo.inParams = append(pInfo.inParams, dependParams(pInfo.inParams)...)
 	if len(pInfo.paths) == 0 || len(pInfo.methods) == 0 {
 		err = fmt.Errorf("the 'goapi.Router' parameter must exist")
 		return
@@ -224,10 +203,103 @@ func (i *includeRouter) handleRouter(routerMethod reflect.Value) (pInfo *pathInf
 	return
 }
 
+// handleRouterTag sets the router by the tag of 'goapi.Router'
+func (i *includeRouter) handleRouterTag(pInfo *pathInfo, tag reflect.StructTag) (err error) {
+	pathStr, pathOk := tag.Lookup(tagPaths)
+	methodStr, methodOk := tag.Lookup(tagMethods)
+	if (i.prefix == "" && pathStr == "") || methodStr == "" || !pathOk || !methodOk {
+		err = fmt.Errorf("the 'goapi.Router' parameter must have tags for 'paths' and 'methods'")
+		return
+	}
+	allMds := allMethods()
+	methods := strings.Split(methodStr, ",")
+	upperMethods := make([]string, len(methods))
+	for k, v := range methods {
+		upperMethods[k] = strings.ToUpper(v)
+	}
+	var noMethods []string
+	for k, v := range upperMethods {
+		if !inArray(v, allMds) {
+			noMethods = append(noMethods, methods[k])
+		}
+	}
+	if len(noMethods) > 0 {
+		err = fmt.Errorf("methods '%v' does not exist, must be in '%v'",
+			strings.Join(noMethods, "', '"), strings.Join(allMds, "', '"))
+		return
+	}
+	paths := splitPaths(pathStr)
+	for k, v := range paths {
+		paths[k] = pathJoin(i.prefix, v)
+	}
+	pInfo.paths = paths
+	pInfo.methods = upperMethods
+	return i.handleRouterOptions(pInfo, tag)
+}
+
+// handleTypedRouter It handles the router of Handle, the method and the path are not split by ',' like the tags
+func (i *includeRouter) handleTypedRouter(pInfo *pathInfo, typed *typedRouter) (err error) {
+	method := strings.ToUpper(typed.method)
+	if !inArray(method, allMethods()) {
+		return fmt.Errorf("the method '%v' must be one of '%v'", typed.method, strings.Join(allMethods(), "', '"))
+	}
+	if typed.path == "" {
+		return fmt.Errorf("the path of the router must be set")
+	}
+	if len(splitPaths(typed.path)) > 1 {
+		return fmt.Errorf("the path '%v' must be one path, ',' is only allowed in the braces", typed.path)
+	}
+	pInfo.paths = []string{pathJoin(i.prefix, typed.path)}
+	pInfo.methods = []string{method}
+	return i.handleRouterOptions(pInfo, typed.tag)
+}
+
+// handleRouterOptions It handles the tags of 'goapi.Router' except 'paths' and 'methods'
+func (i *includeRouter) handleRouterOptions(pInfo *pathInfo, tag reflect.StructTag) (err error) {
+	deprecated := false
+	deprecatedStr := tag.Get("deprecated")
+	if deprecatedStr != "" {
+		if deprecated, err = strconv.ParseBool(tag.Get("deprecated")); err != nil {
+			return
+		}
+	}
+	pInfo.name = tag.Get(tagName)
+	pInfo.summary = tag.Get(tagSummary)
+	pInfo.desc = tag.Get(tagDesc)
+	pInfo.deprecated = deprecated
+	if tags := tag.Get(tagTags); tags != "" {
+		pInfo.tags = strings.Split(tags, ",")
+	}
+	if names := tag.Get(tagMiddle); names != "" {
+		// copy the middlewares of the group, it is shared by the routers
+		pInfo.middlewareInfos = append([]middlewareInfo{}, pInfo.middlewareInfos...)
+		for _, name := range strings.Split(names, ",") {
+			pInfo.middlewareInfos = append(pInfo.middlewareInfos, middlewareInfo{name: strings.TrimSpace(name)})
+		}
+	}
+	if names := tag.Get(tagSkip); names != "" {
+		for _, name := range strings.Split(names, ",") {
+			pInfo.skipMiddlewares = append(pInfo.skipMiddlewares, strings.TrimSpace(name))
+		}
+	}
+	return
+}
+
 func (i *includeRouter) parseIn(field reflect.StructField, deeps []int, securityType InType) (params []*inParam, err error) {
 	if field.Name[0] < 'A' || field.Name[0] > 'Z' {
 		return
 	}
+	// handle dependency
+	if p := i.providers.get(field.Type); p != nil && !hasInTag(field) {
+		params = append(params, &inParam{
+			inType:       inTypeDepend,
+			structField:  field,
+			deeps:        deeps,
+			parentInType: securityType,
+			depend:       p,
+		})
+		return
+	}
 	// handle security
 	var securityInType InType
 	if field.Type.Implements(securityTypeHTTPBasic) {
@@ -338,3 +410,13 @@ func (i *includeRouter) parseIn(field reflect.StructField, deeps []int, security
 	}
 	return
 }
+
+// hasInTag returns whether the field has the tag of the parameter, such as 'query' and 'body'
+func hasInTag(field reflect.StructField) bool {
+	for _, inType := range InType("").List() {
+		if field.Tag.Get(inType.Tag()) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package goapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultLifespanTimeout   = 30 * time.Second
+	defaultBackgroundTimeout = 30 * time.Second
+)
+
+// LifespanFunc It is a hook executed when the API starts up or shuts down
+// The ctx has the deadline of LifespanTimeout, log is the Logger set by SetLogger
+type LifespanFunc func(ctx context.Context, log Logger) error
+
+// OnStartup It is a function for adding hooks executed in order before the server starts listening.
+// Any hook error will abort startup, and Run returns the error
+func (a *API) OnStartup(hooks ...LifespanFunc) {
+	a.startupHooks = append(a.startupHooks, hooks...)
+}
+
+// OnShutdown It is a function for adding hooks executed in reverse order after the server stops serving
+func (a *API) OnShutdown(hooks ...LifespanFunc) {
+	a.shutdownHooks = append(a.shutdownHooks, hooks...)
+}
+
+func (a *API) lifespanTimeout() time.Duration {
+	if a.LifespanTimeout > 0 {
+		return a.LifespanTimeout
+	}
+	return defaultLifespanTimeout
+}
+
+func (a *API) backgroundTimeout() time.Duration {
+	if a.BackgroundTimeout > 0 {
+		return a.BackgroundTimeout
+	}
+	return defaultBackgroundTimeout
+}
+
+// drainBackground waits for the background tasks with the deadline of BackgroundTimeout
+func (a *API) drainBackground() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.backgroundTimeout())
+	defer cancel()
+	return a.background.drain(ctx)
+}
+
+// startup executes the startup hooks only once
+func (a *API) startup() (err error) {
+	a.lifespanMux.Lock()
+	defer a.lifespanMux.Unlock()
+	if a.isStarted {
+		return
+	}
+	a.background.open()
+	if len(a.startupHooks) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), a.lifespanTimeout())
+		defer cancel()
+		a.writeLogInfo(a.log, "Waiting for application startup")
+		for _, hook := range a.startupHooks {
+			if err = hook(ctx, a.log); err != nil {
+				err = fmt.Errorf("application startup failed: %w", err)
+				if a.log != nil {
+					a.log.Error("%v", err)
+				}
+				return
+			}
+		}
+		a.writeLogInfo(a.log, "Application startup complete")
+	}
+	a.isStarted = true
+	return
+}
+
+// shutdown executes the shutdown hooks in reverse order, only when startup has been completed
+func (a *API) shutdown() (err error) {
+	a.lifespanMux.Lock()
+	defer a.lifespanMux.Unlock()
+	if !a.isStarted {
+		return
+	}
+	a.isStarted = false
+	defer a.providers.shutdown()
+	// the background tasks may use the resources closed by the hooks
+	if err = a.drainBackground(); err != nil && a.log != nil {
+		a.log.Error("%v", err)
+	}
+	if len(a.shutdownHooks) == 0 {
+		return
+	}
+	// the hooks have their own deadline, which is not used up by the background tasks
+	ctx, cancel := context.WithTimeout(context.Background(), a.lifespanTimeout())
+	defer cancel()
+	a.writeLogInfo(a.log, "Waiting for application shutdown")
+	for i := len(a.shutdownHooks) - 1; i >= 0; i-- {
+		if hookErr := a.shutdownHooks[i](ctx, a.log); hookErr != nil {
+			if a.log != nil {
+				a.log.Error("application shutdown failed: %v", hookErr)
+			}
+			if err == nil {
+				err = hookErr
+			}
+		}
+	}
+	a.writeLogInfo(a.log, "Application shutdown complete")
+	return
+}
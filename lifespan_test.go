@@ -0,0 +1,93 @@
+package goapi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLifespanHooksOrder(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	var calls []string
+	hook := func(name string) LifespanFunc {
+		return func(ctx context.Context, log Logger) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Errorf("hook %v: context should have a deadline", name)
+			}
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	api.OnStartup(hook("db"), hook("cache"))
+	api.OnShutdown(hook("close db"), hook("close cache"))
+	addr := freeAddr(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.Run(addr)
+	}()
+	waitServing(t, addr)
+	if err := api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-runErr; err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"db", "cache", "close cache", "close db"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("hook calls: got %v want %v", calls, want)
+	}
+}
+
+func TestLifespanStartupErrorAbortsRun(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	startupErr := errors.New("db unavailable")
+	shutdownCalled := false
+	api.OnStartup(func(ctx context.Context, log Logger) error {
+		return startupErr
+	})
+	api.OnShutdown(func(ctx context.Context, log Logger) error {
+		shutdownCalled = true
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- api.Run(freeAddr(t))
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, startupErr) {
+			t.Fatalf("Run error: got %v want %v", err, startupErr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run should return when a startup hook fails")
+	}
+	if shutdownCalled {
+		t.Fatal("shutdown hooks should not run when startup failed")
+	}
+}
+
+func TestLifespanHookReceivesLogger(t *testing.T) {
+	api := New(false)
+	logger := nopLogger{}
+	api.SetLogger(logger)
+	api.LifespanTimeout = time.Second
+	var got Logger
+	api.OnStartup(func(ctx context.Context, log Logger) error {
+		got = log
+		deadline, _ := ctx.Deadline()
+		if time.Until(deadline) > time.Second {
+			t.Errorf("deadline should follow LifespanTimeout")
+		}
+		return nil
+	})
+	if err := api.startup(); err != nil {
+		t.Fatal(err)
+	}
+	if got != logger {
+		t.Fatalf("hook logger: got %T want the logger set by SetLogger", got)
+	}
+}
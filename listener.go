@@ -0,0 +1,115 @@
+package goapi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const systemdListenFdsStart = 3
+
+// RunListener attaches the router to a http.Server and starts serving HTTP requests on the listener.
+// The http.Server is configured by Server, see ServerConfig
+// Note: this method will block the calling goroutine indefinitely unless an error happens or Shutdown is called.
+func (a *API) RunListener(ln net.Listener) (err error) {
+	addr := ln.Addr().String()
+	if ln.Addr().Network() == "unix" {
+		addr = "unix:" + addr
+	}
+	return a.run("http", addr, func() (net.Listener, error) {
+		return ln, nil
+	}, func(server *http.Server, ln net.Listener) error {
+		return server.Serve(ln)
+	}, nil)
+}
+
+// RunUnix attaches the router to a http.Server and starts serving HTTP requests on the Unix domain socket path.
+// A stale socket file left by a previous process is removed, the socket file is set to mode after listening.
+// Note: this method will block the calling goroutine indefinitely unless an error happens or Shutdown is called.
+func (a *API) RunUnix(path string, mode os.FileMode) (err error) {
+	return a.run("http", "unix:"+path, func() (ln net.Listener, err error) {
+		if err = removeStaleUnixSocket(path); err != nil {
+			return
+		}
+		if ln, err = net.Listen("unix", path); err != nil {
+			return
+		}
+		if err = os.Chmod(path, mode); err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+		return
+	}, func(server *http.Server, ln net.Listener) error {
+		return server.Serve(ln)
+	}, nil)
+}
+
+// removeStaleUnixSocket removes the socket file when no process is listening on it
+func removeStaleUnixSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("the unix socket path '%v' already exists and is not a socket", path)
+	}
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("the unix socket path '%v' is already in use", path)
+	}
+	return os.Remove(path)
+}
+
+// SystemdListeners It returns the listeners passed by systemd socket activation, in the order of LISTEN_FDS
+// An empty list is returned when the process is not socket activated. The listeners can be used by RunListener.
+//
+// example:
+//
+//	listeners, err := goapi.SystemdListeners()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if len(listeners) > 0 {
+//		log.Fatal(api.RunListener(listeners[0]))
+//	}
+func SystemdListeners() (listeners []net.Listener, err error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	nfds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || nfds <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	// the variables are not passed to child processes
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+	for i := 0; i < nfds; i++ {
+		name := "LISTEN_FD_" + strconv.Itoa(systemdListenFdsStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		file := os.NewFile(uintptr(systemdListenFdsStart+i), name)
+		var ln net.Listener
+		ln, err = net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("the systemd file descriptor '%v' is not a listener: %w", name, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	return
+}
@@ -0,0 +1,104 @@
+package goapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRunListener(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.RunListener(ln)
+	}()
+	resp, err := http.Get("http://" + ln.Addr().String() + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != `"pong"` {
+		t.Fatalf("response: got %q", body)
+	}
+	if err = api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-runErr; err != nil {
+		t.Fatalf("RunListener after Shutdown: got %v want nil", err)
+	}
+}
+
+func TestRunUnixRemovesStaleSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix domain sockets are not tested on windows")
+	}
+	path := filepath.Join(t.TempDir(), "api.sock")
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.RunUnix(path, 0o660)
+	}()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return net.Dial("unix", path)
+		},
+	}}
+	var resp *http.Response
+	for i := 0; i < 100; i++ {
+		if resp, err = client.Get("http://unix/ping"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status: got %v", resp.StatusCode)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o660 {
+		t.Fatalf("socket mode: got %v want %v", info.Mode().Perm(), os.FileMode(0o660))
+	}
+	if err = api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-runErr; err != nil {
+		t.Fatalf("RunUnix after Shutdown: got %v want nil", err)
+	}
+}
+
+func TestSystemdListenersNotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	listeners, err := SystemdListeners()
+	if err != nil || len(listeners) != 0 {
+		t.Fatalf("got %v, %v want no listeners", listeners, err)
+	}
+}
@@ -0,0 +1,86 @@
+package goapi
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// middlewareName It is the name of the middleware registered by API.RegisterMiddleware
+type middlewareName string
+
+// middlewareInfo It is a middleware of the group, the name is not empty for the middlewares of UseMiddleware
+type middlewareInfo struct {
+	name   string
+	handle HandleFunc
+}
+
+func newMiddlewareInfos(middlewares []HandleFunc) []middlewareInfo {
+	infos := make([]middlewareInfo, len(middlewares))
+	for k, middleware := range middlewares {
+		infos[k].handle = middleware
+	}
+	return infos
+}
+
+// RegisterMiddleware It is to register a middleware with a name, the name is used by RouterGroup.UseMiddleware and
+// the 'middlewares' and 'skip' tags of 'goapi.Router', the names are resolved by Build.
+// A name can only be registered once, the duplicated one is not registered and returned by Build as an error.
+// The name 'logger' is registered by Default for LoggerMiddleware
+//
+// example:
+//
+//	api.RegisterMiddleware("auth", authMiddleware)
+//	api.UseMiddleware("auth")
+//	router goapi.Router `paths:"/login" methods:"POST" skip:"auth" middlewares:"audit"`
+func (a *API) RegisterMiddleware(name string, middleware HandleFunc) {
+	if exists, ok := a.middlewareMap[name]; ok {
+		a.middlewareErrs = append(a.middlewareErrs, fmt.Errorf("the middleware '%v' is duplicated, pos: %v and %v",
+			name, middlewarePos(exists), middlewarePos(middleware)))
+		return
+	}
+	if a.middlewareMap == nil {
+		a.middlewareMap = map[string]HandleFunc{}
+	}
+	a.middlewareMap[name] = middleware
+}
+
+// middlewarePos returns the name of the function of the middleware
+func middlewarePos(fn HandleFunc) string {
+	if fn == nil {
+		return "nil"
+	}
+	return runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+}
+
+// UseMiddleware It is to add the middlewares registered by API.RegisterMiddleware, they can be skipped by the 'skip' tag
+func (r *RouterGroup) UseMiddleware(names ...string) {
+	for _, name := range names {
+		r.handlers = append(r.handlers, middlewareName(name))
+	}
+}
+
+// resolveMiddlewares returns the middlewares in order, the named middlewares are replaced by the registered ones,
+// and the skipped ones are removed
+func (h *handler) resolveMiddlewares(infos []middlewareInfo, skips []string) (middlewares []HandleFunc, err error) {
+	for _, name := range skips {
+		if _, ok := h.api.middlewareMap[name]; !ok {
+			return nil, fmt.Errorf("the skipped middleware '%v' is not registered", name)
+		}
+	}
+	for _, info := range infos {
+		if info.name == "" {
+			middlewares = append(middlewares, info.handle)
+			continue
+		}
+		middleware, ok := h.api.middlewareMap[info.name]
+		if !ok {
+			return nil, fmt.Errorf("the middleware '%v' is not registered", info.name)
+		}
+		if inArray(info.name, skips) {
+			continue
+		}
+		middlewares = append(middlewares, middleware)
+	}
+	return
+}
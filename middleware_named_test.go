@@ -0,0 +1,87 @@
+package goapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type namedMiddlewareTestRouter struct{}
+
+func (r *namedMiddlewareTestRouter) Users(input struct {
+	router Router `paths:"/users" methods:"GET" middlewares:"audit"`
+}) string {
+	return "users"
+}
+
+func (r *namedMiddlewareTestRouter) Health(input struct {
+	router Router `paths:"/health" methods:"GET" skip:"auth"`
+}) string {
+	return "ok"
+}
+
+type unknownMiddlewareTestRouter struct{}
+
+func (r *unknownMiddlewareTestRouter) Index(input struct {
+	router Router `paths:"/index" methods:"GET" skip:"none"`
+}) {
+}
+
+func TestNamedMiddlewares(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	mark := func(name string) HandleFunc {
+		return func(ctx *Context) {
+			ctx.Writer.Header().Add("X-Trace", name)
+			ctx.Next()
+		}
+	}
+	api.AddMiddleware(mark("first"))
+	api.UseMiddleware("auth")
+	api.AddMiddleware(mark("last"))
+	api.RegisterMiddleware("auth", mark("auth"))
+	api.RegisterMiddleware("audit", mark("audit"))
+	api.IncludeRouter(&namedMiddlewareTestRouter{}, "", false)
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"/users":  "first,auth,last,audit",
+		"/health": "first,last",
+		"/none":   "first,auth,last",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := strings.Join(w.Header().Values("X-Trace"), ","); got != want {
+			t.Errorf("%v: got middlewares %q want %q", path, got, want)
+		}
+	}
+
+	api = New(false)
+	api.SetLogger(nil)
+	api.UseMiddleware("missing")
+	api.IncludeRouter(&unknownMiddlewareTestRouter{}, "", false)
+	_, err = api.Build()
+	for _, want := range []string{"the middleware 'missing' is not registered", "the skipped middleware 'none' is not registered"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Build error should contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestRegisterMiddlewareDuplicated(t *testing.T) {
+	api := Default(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	// the 'logger' of Default is not replaced
+	api.RegisterMiddleware("logger", func(ctx *Context) {
+		ctx.Next()
+	})
+	_, err := api.Build()
+	if err == nil || !strings.Contains(err.Error(), "the middleware 'logger' is duplicated") {
+		t.Fatalf("Build error: got %v", err)
+	}
+}
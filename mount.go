@@ -0,0 +1,146 @@
+package goapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/goodluckxu-go/goapi/v2/openapi"
+)
+
+type mountInfo struct {
+	prefix      string
+	handler     http.Handler
+	docs        []openapi.PathItem
+	isDocs      bool
+	groupPrefix string
+	docsPath    string
+	childPath   string
+	middlewares []middlewareInfo
+}
+
+// Mount It is to serve the http.Handler for all the methods of the prefix and the paths under it,
+// the prefix is stripped from the path of the request, such as '/legacy/users' to '/users'.
+// The middlewares of the group are executed before the handler.
+// The docs are merged in order and shown as the path item of the prefix
+//
+// example:
+//
+//	api.Mount("/metrics", promhttp.Handler())
+//	api.Mount("/legacy", legacyRouter, openapi.PathItem{Summary: "The legacy routers"})
+func (r *RouterGroup) Mount(prefix string, handler http.Handler, docs ...openapi.PathItem) {
+	r.handlers = append(r.handlers, &mountInfo{
+		prefix:      prefix,
+		handler:     handler,
+		docs:        docs,
+		isDocs:      r.isDocs,
+		groupPrefix: r.groupPrefix,
+		docsPath:    r.docsPath,
+		childPath:   r.childPath,
+		middlewares: append(r.middlewares, r.getMiddlewares()...),
+	})
+}
+
+func (m *mountInfo) returnObj() (obj returnObjResult, err error) {
+	prefix := strings.TrimSuffix(pathJoin(m.groupPrefix, m.prefix), "/")
+	paths := []string{prefix + "/", prefix + "/{path:*}"}
+	if prefix != "" {
+		paths = append([]string{prefix}, paths...)
+	}
+	hType := reflect.TypeOf(m.handler)
+	if hType == nil {
+		err = fmt.Errorf("the handler of Mount '%v' cannot be nil", m.prefix)
+		return
+	}
+	pos := fmt.Sprintf("%v.%v", hType.PkgPath(), hType.Name())
+	if hType.Kind() == reflect.Ptr {
+		hType = hType.Elem()
+		pos = fmt.Sprintf("%v.(*%v)", hType.PkgPath(), hType.Name())
+	}
+	var docs *openapi.PathItem
+	if len(m.docs) > 0 {
+		docs = &openapi.PathItem{}
+		for _, item := range m.docs {
+			mergePathItem(docs, item)
+		}
+	}
+	handler := m.handler
+	obj.paths = append(obj.paths, &pathInfo{
+		paths:   paths,
+		methods: allMethods(),
+		pos:     pos,
+		handle: func(ctx *Context) {
+			handler.ServeHTTP(ctx.Writer, stripPrefixRequest(ctx.Request, prefix))
+		},
+		groupPrefix:     m.groupPrefix,
+		docsPath:        m.docsPath,
+		childPath:       m.childPath,
+		isDocs:          m.isDocs && docs != nil,
+		middlewareInfos: m.middlewares,
+		mountDocs:       docs,
+	})
+	return
+}
+
+// stripPrefixRequest returns a shallow copy of the request with the prefix removed from the path, like http.StripPrefix
+func stripPrefixRequest(r *http.Request, prefix string) *http.Request {
+	p := strings.TrimPrefix(r.URL.Path, prefix)
+	if p == "" {
+		p = "/"
+	}
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = p
+	if r.URL.RawPath != "" {
+		rp := strings.TrimPrefix(r.URL.RawPath, prefix)
+		if rp == "" {
+			rp = "/"
+		}
+		r2.URL.RawPath = rp
+	}
+	return r2
+}
+
+// mergePathItem sets the non-empty fields of src to dst
+func mergePathItem(dst *openapi.PathItem, src openapi.PathItem) {
+	if src.Ref != "" {
+		dst.Ref = src.Ref
+	}
+	if src.Summary != "" {
+		dst.Summary = src.Summary
+	}
+	if src.Description != "" {
+		dst.Description = src.Description
+	}
+	operations := []struct {
+		dst **openapi.Operation
+		src *openapi.Operation
+	}{
+		{&dst.Get, src.Get}, {&dst.Put, src.Put}, {&dst.Post, src.Post}, {&dst.Delete, src.Delete},
+		{&dst.Options, src.Options}, {&dst.Head, src.Head}, {&dst.Patch, src.Patch}, {&dst.Trace, src.Trace},
+		{&dst.Query, src.Query},
+	}
+	for _, operation := range operations {
+		if operation.src != nil {
+			*operation.dst = operation.src
+		}
+	}
+	for k, v := range src.AdditionalOperations {
+		if dst.AdditionalOperations == nil {
+			dst.AdditionalOperations = map[string]*openapi.Operation{}
+		}
+		dst.AdditionalOperations[k] = v
+	}
+	dst.Servers = append(dst.Servers, src.Servers...)
+	dst.Parameters = append(dst.Parameters, src.Parameters...)
+	for k, v := range src.Extensions {
+		if dst.Extensions == nil {
+			dst.Extensions = map[string]any{}
+		}
+		dst.Extensions[k] = v
+	}
+}
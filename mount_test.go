@@ -0,0 +1,71 @@
+package goapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goodluckxu-go/goapi/v2/openapi"
+)
+
+func TestMount(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	api.GenerateRequestID = true
+	api.UseXRequestIDHeader = true
+	legacy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Method+" "+r.URL.Path)
+	})
+	group := api.Group("/api", true)
+	group.AddMiddleware(func(ctx *Context) {
+		ctx.Writer.Header().Set("X-Group", "1")
+		ctx.Next()
+	})
+	responses := &openapi.Responses{}
+	responses.Set("200", &openapi.Response{Description: "OK"})
+	group.Mount("/legacy", legacy, openapi.PathItem{Summary: "legacy"}, openapi.PathItem{
+		Get: &openapi.Operation{OperationId: "getLegacy", Responses: responses},
+	})
+	api.Mount("/metrics", legacy)
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		group  bool
+	}{
+		{method: http.MethodGet, path: "/api/legacy", body: "GET /", group: true},
+		{method: http.MethodPost, path: "/api/legacy/", body: "POST /", group: true},
+		{method: http.MethodDelete, path: "/api/legacy/users/7", body: "DELETE /users/7", group: true},
+		{method: http.MethodGet, path: "/metrics", body: "GET /"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != tt.body {
+			t.Errorf("%v %v: got %v %q want %q", tt.method, tt.path, w.Code, w.Body.String(), tt.body)
+		}
+		if (w.Header().Get("X-Group") == "1") != tt.group {
+			t.Errorf("%v %v: the group middleware executed: %v", tt.method, tt.path, !tt.group)
+		}
+		if w.Header().Get("X-Request-ID") == "" {
+			t.Errorf("%v %v: the request ID is missing", tt.method, tt.path)
+		}
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	doc := w.Body.String()
+	for _, want := range []string{`"/api/legacy":{`, `"operationId":"getLegacy"`, `"summary":"legacy"`} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("openapi.json should contain %v, got %v", want, doc)
+		}
+	}
+	if strings.Contains(doc, `"/metrics"`) {
+		t.Error("openapi.json should not contain Mount without docs")
+	}
+}
@@ -0,0 +1,190 @@
+//go:build linux || darwin
+
+package goapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	preforkChildEnv = "GOAPI_PREFORK_CHILD"
+	// preforkRestartDelay avoids restarting a child that crashes at startup too frequently
+	preforkRestartDelay = time.Second
+)
+
+// RunPrefork starts n child processes, each of them binds addr with SO_REUSEPORT and serves the same routers.
+// The parent process supervises the children and restarts any that crash, n <= 0 means runtime.NumCPU().
+// The OnStartup and OnShutdown hooks are executed in the child processes.
+// SIGTERM, SIGINT or Shutdown stop the children gracefully, then RunPrefork returns nil in the parent process.
+// Note: addr must have a fixed port, this method is only supported on linux and darwin.
+func (a *API) RunPrefork(addr string, n int) (err error) {
+	a.addr = addr
+	if os.Getenv(preforkChildEnv) != "" {
+		return a.runPreforkChild()
+	}
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	return a.runPreforkParent(n)
+}
+
+func (a *API) runPreforkChild() (err error) {
+	ppid := os.Getppid()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-signals:
+			case <-ticker.C:
+				if os.Getppid() == ppid {
+					continue
+				}
+				// the parent process has exited
+			}
+			ctx, cancel := a.shutdownContext()
+			defer cancel()
+			if shutdownErr := a.Shutdown(ctx); shutdownErr != nil && a.log != nil {
+				a.log.Error("shutdown failed: %v", shutdownErr)
+			}
+			return
+		}
+	}()
+	a.listenBind = listenReusePort
+	return a.run("http", a.addr, func() (net.Listener, error) {
+		return listenReusePort(a.addr)
+	}, func(server *http.Server, ln net.Listener) error {
+		return server.Serve(ln)
+	}, nil)
+}
+
+func (a *API) runPreforkParent(n int) (err error) {
+	a.writeLogInfo(a.log, "Started parent process [%v]", ColorDebug(strconv.Itoa(os.Getpid())))
+	if _, err = a.Build(); err != nil {
+		return
+	}
+	// check that the address can be bound before starting the children
+	ln, err := listenReusePort(a.addr)
+	if err != nil {
+		return
+	}
+	_ = ln.Close()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+	children := map[*exec.Cmd]time.Time{}
+	exited := make(chan *exec.Cmd, n)
+	start := func() error {
+		cmd, startErr := a.startPreforkChild()
+		if startErr != nil {
+			return startErr
+		}
+		children[cmd] = time.Now()
+		go func() {
+			_ = cmd.Wait()
+			exited <- cmd
+		}()
+		return nil
+	}
+	defer func() {
+		for cmd := range children {
+			_ = cmd.Process.Signal(syscall.SIGTERM)
+		}
+		for len(children) > 0 {
+			delete(children, <-exited)
+		}
+	}()
+	for i := 0; i < n; i++ {
+		if err = start(); err != nil {
+			return
+		}
+	}
+	a.writeLogInfo(a.log, "GoAPI running on http://%v with %v processes (Press CTRL+C to quit)", a.addr, n)
+	// the exited children are restarted by the timer, so that the signals are handled while waiting,
+	// the children failed to restart are retried by the next timer
+	var restarts int
+	var restart <-chan time.Time
+	for {
+		select {
+		case sig := <-signals:
+			a.writeLogInfo(a.log, "Received signal %v, stopping child processes", sig)
+			return
+		case <-a.shutdownDone:
+			a.writeLogInfo(a.log, "Stopping child processes")
+			return
+		case cmd := <-exited:
+			startTime := children[cmd]
+			delete(children, cmd)
+			if a.log != nil {
+				a.log.Error("child process [%v] exited: %v, restarting", cmd.Process.Pid, cmd.ProcessState)
+			}
+			restarts++
+			if restart == nil {
+				restart = time.After(preforkRestartDelay - time.Since(startTime))
+			}
+		case <-restart:
+			restart = nil
+			for restarts > 0 {
+				if startErr := start(); startErr != nil {
+					if a.log != nil {
+						a.log.Error("restart child process failed: %v", startErr)
+					}
+					restart = time.After(preforkRestartDelay)
+					break
+				}
+				restarts--
+			}
+		}
+	}
+}
+
+// startPreforkChild starts the current binary as a child process
+func (a *API) startPreforkChild() (cmd *exec.Cmd, err error) {
+	path, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return
+	}
+	cmd = exec.Command(path, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), preforkChildEnv+"=1")
+	if err = cmd.Start(); err != nil {
+		return
+	}
+	a.writeLogInfo(a.log, "Started child process [%v]", ColorDebug(strconv.Itoa(cmd.Process.Pid)))
+	return
+}
+
+func listenReusePort(addr string) (net.Listener, error) {
+	lc := net.ListenConfig{
+		Control: func(network, address string, c syscall.RawConn) (err error) {
+			controlErr := c.Control(func(fd uintptr) {
+				err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+			})
+			if controlErr != nil {
+				return controlErr
+			}
+			return
+		},
+	}
+	return lc.Listen(context.Background(), "tcp", addr)
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+
+package goapi
+
+import (
+	"errors"
+)
+
+// RunPrefork starts n child processes serving the same routers with SO_REUSEPORT, only supported on linux and darwin
+func (a *API) RunPrefork(addr string, n int) (err error) {
+	return errors.New("RunPrefork is only supported on linux and darwin")
+}
@@ -0,0 +1,111 @@
+//go:build linux || darwin
+
+package goapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const preforkTestAddrEnv = "GOAPI_PREFORK_TEST_ADDR"
+
+type preforkTestRouter struct{}
+
+func (p *preforkTestRouter) Pid(input struct {
+	router Router `paths:"/pid" methods:"GET"`
+}) string {
+	return strconv.Itoa(os.Getpid())
+}
+
+func (p *preforkTestRouter) Crash(input struct {
+	router Router `paths:"/crash" methods:"GET"`
+}) {
+	os.Exit(3)
+}
+
+// TestPreforkHelperProcess It is not a real test, it serves by RunPrefork in the child processes started by
+// TestRunPreforkRestartsCrashedChild
+func TestPreforkHelperProcess(t *testing.T) {
+	if os.Getenv(preforkChildEnv) == "" || os.Getenv(preforkTestAddrEnv) == "" {
+		return
+	}
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&preforkTestRouter{}, "", false)
+	if err := api.RunPrefork(os.Getenv(preforkTestAddrEnv), 0); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestRunPreforkRestartsCrashedChild(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&preforkTestRouter{}, "", false)
+	addr := freeAddr(t)
+	t.Setenv(preforkTestAddrEnv, addr)
+	// the child processes only run TestPreforkHelperProcess
+	args := os.Args
+	os.Args = []string{args[0], "-test.run=^TestPreforkHelperProcess$"}
+	defer func() {
+		os.Args = args
+	}()
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.RunPrefork(addr, 2)
+	}()
+	client := &http.Client{
+		Timeout:   time.Second,
+		Transport: &http.Transport{DisableKeepAlives: true},
+	}
+	get := func(path string) string {
+		resp, err := client.Get("http://" + addr + path)
+		if err != nil {
+			return ""
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	pids := map[string]struct{}{}
+	for i := 0; i < 500 && len(pids) < 2; i++ {
+		if pid := get("/pid"); pid != "" {
+			pids[pid] = struct{}{}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(pids) < 2 {
+		t.Fatalf("the child processes did not start, pids: %v", pids)
+	}
+	if _, ok := pids[strconv.Quote(strconv.Itoa(os.Getpid()))]; ok {
+		t.Fatal("the parent process should not serve")
+	}
+	get("/crash")
+	restarted := false
+	for i := 0; i < 500 && !restarted; i++ {
+		if pid := get("/pid"); pid != "" {
+			_, ok := pids[pid]
+			restarted = !ok
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !restarted {
+		t.Fatalf("the crashed child process was not restarted, pids: %v", pids)
+	}
+	if err := api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Fatalf("RunPrefork after Shutdown: got %v want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunPrefork did not stop the child processes")
+	}
+}
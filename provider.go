@@ -0,0 +1,343 @@
+package goapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// Scope It is the scope of the value created by the provider of API.Provide
+type Scope uint8
+
+const (
+	ScopeRequest   Scope = iota // created once for each request and shared by the fields of the request, default
+	ScopeSingleton              // created once by the first request using it and shared by all the requests
+)
+
+var typeCleanup = reflect.TypeOf(func() {})
+
+const (
+	providerArgCtx    uint8 = iota + 1 // *goapi.Context
+	providerArgDepend                  // the value of other provider
+	providerArgParams                  // the structure declaring the parameters
+)
+
+// providers It is the providers registered by API.Provide, it is shared by the groups of the API
+type providers struct {
+	mux      sync.Mutex
+	list     []*provider
+	types    map[reflect.Type]*provider
+	errs     []error  // the errors of API.Provide, returned by Build
+	cleanups []func() // the cleanups of the singletons, executed when the API shuts down
+}
+
+// provider It is a provider function registered by API.Provide
+type provider struct {
+	fn         reflect.Value
+	outType    reflect.Type
+	scope      Scope
+	pos        string
+	hasCleanup bool
+	hasErr     bool
+	args       []providerArg
+	params     []*inParam // the parameters of the structure, the deeps are relative to the structure
+	resolved   bool
+	owner      *providers
+	// the value of ScopeSingleton
+	mux   sync.Mutex
+	value reflect.Value
+	done  bool
+}
+
+type providerArg struct {
+	kind   uint8
+	_type  reflect.Type
+	depend *provider
+}
+
+// Provide It is to register a provider function, the fields without tags of the router inputs whose type is
+// the first result of the provider are filled by it, the providers are resolved by Build.
+// The provider can accept '*goapi.Context', the values of other providers and a structure declaring the
+// 'query', 'header' and 'cookie' parameters, these parameters are shown in the OpenAPI operations of the routers.
+// The provider returns the value, an optional cleanup function and an optional error in order,
+// the cleanups are executed in reverse order after the router and the middlewares return, before the response
+// is finished and flushed by the server, or when the API shuts down for ScopeSingleton.
+// The error of the provider is responded as it is if it is *HTTPError, otherwise it is written by the log
+// and responded as a generic 500.
+// The scope is ScopeRequest by default, the providers of ScopeSingleton can only accept the values of
+// other providers of ScopeSingleton
+//
+// example:
+//
+//	api.Provide(func() (*sql.DB, error) { return sql.Open("mysql", dsn) }, goapi.ScopeSingleton)
+//	api.Provide(func(ctx *goapi.Context, db *sql.DB) (*sql.Tx, func(), error) { ... })
+//	Tx *sql.Tx // the field of the router input
+func (a *API) Provide(provider any, scope ...Scope) {
+	a.providers.add(provider, scope...)
+}
+
+func (ps *providers) add(fn any, scope ...Scope) {
+	ps.mux.Lock()
+	defer ps.mux.Unlock()
+	p, err := newProvider(fn, scope...)
+	if err != nil {
+		ps.errs = append(ps.errs, err)
+		return
+	}
+	if exists, ok := ps.types[p.outType]; ok {
+		ps.errs = append(ps.errs, fmt.Errorf("the provider of '%v' is duplicated, pos: %v and %v",
+			p.outType, exists.pos, p.pos))
+		return
+	}
+	if ps.types == nil {
+		ps.types = map[reflect.Type]*provider{}
+	}
+	p.owner = ps
+	ps.types[p.outType] = p
+	ps.list = append(ps.list, p)
+}
+
+func newProvider(fn any, scope ...Scope) (p *provider, err error) {
+	fnVal := reflect.ValueOf(fn)
+	if fnVal.Kind() != reflect.Func || fnVal.IsNil() {
+		return nil, fmt.Errorf("the provider must be a function, has type '%T'", fn)
+	}
+	p = &provider{fn: fnVal, pos: runtime.FuncForPC(fnVal.Pointer()).Name()}
+	if len(scope) > 0 {
+		p.scope = scope[0]
+	}
+	fType := fnVal.Type()
+	numOut := fType.NumOut()
+	if fType.IsVariadic() || numOut == 0 || numOut > 3 {
+		return nil, fmt.Errorf("the provider must return the value, an optional cleanup 'func()' and an optional error, pos: %v", p.pos)
+	}
+	p.outType = fType.Out(0)
+	if p.outType == typeError || p.outType == typeCleanup || p.outType == typeContext {
+		return nil, fmt.Errorf("the provider cannot provide '%v', pos: %v", p.outType, p.pos)
+	}
+	for j := 1; j < numOut; j++ {
+		switch out := fType.Out(j); {
+		case out == typeCleanup && j == 1:
+			p.hasCleanup = true
+		case out == typeError && j == numOut-1:
+			p.hasErr = true
+		default:
+			return nil, fmt.Errorf("the provider must return the value, an optional cleanup 'func()' and an optional error, pos: %v", p.pos)
+		}
+	}
+	p.args = make([]providerArg, fType.NumIn())
+	for j := range p.args {
+		p.args[j]._type = fType.In(j)
+		if p.args[j]._type == typeContext {
+			p.args[j].kind = providerArgCtx
+		}
+	}
+	return
+}
+
+// get returns the provider of the type, nil if not registered
+func (ps *providers) get(fType reflect.Type) *provider {
+	if ps == nil {
+		return nil
+	}
+	return ps.types[fType]
+}
+
+// resolve It resolves the arguments of the providers registered after the last Build, it is called by Build
+func (ps *providers) resolve() error {
+	if ps == nil {
+		return nil
+	}
+	ps.mux.Lock()
+	defer ps.mux.Unlock()
+	errs := append([]error(nil), ps.errs...)
+	var resolving []*provider
+	for _, p := range ps.list {
+		if p.resolved {
+			continue
+		}
+		if err := ps.resolveArgs(p); err != nil {
+			errs = append(errs, fmt.Errorf("%v, pos: %v", err, p.pos))
+			continue
+		}
+		resolving = append(resolving, p)
+	}
+	for _, p := range resolving {
+		if cycle := p.findCycle(nil); cycle != "" {
+			errs = append(errs, fmt.Errorf("the providers depend on each other circularly: %v, pos: %v", cycle, p.pos))
+		}
+	}
+	if len(errs) > 0 {
+		return newBuildError(errs)
+	}
+	for _, p := range resolving {
+		p.resolved = true
+	}
+	return nil
+}
+
+func (ps *providers) resolveArgs(p *provider) (err error) {
+	p.params = nil
+	hasParams := false
+	for k := range p.args {
+		arg := &p.args[k]
+		if arg.kind == providerArgCtx {
+			if p.scope == ScopeSingleton {
+				return fmt.Errorf("the provider of ScopeSingleton cannot accept '*goapi.Context'")
+			}
+			continue
+		}
+		if arg.depend = ps.types[arg._type]; arg.depend != nil {
+			arg.kind = providerArgDepend
+			if p.scope == ScopeSingleton && arg.depend.scope != ScopeSingleton {
+				return fmt.Errorf("the provider of ScopeSingleton cannot accept the value '%v' of ScopeRequest", arg._type)
+			}
+			continue
+		}
+		if arg._type.Kind() != reflect.Struct {
+			return fmt.Errorf("the provider of '%v' is not registered", arg._type)
+		}
+		if p.scope == ScopeSingleton {
+			return fmt.Errorf("the provider of ScopeSingleton cannot accept the parameters '%v'", arg._type)
+		}
+		if hasParams {
+			return fmt.Errorf("the provider can only accept one structure of the parameters")
+		}
+		hasParams = true
+		arg.kind = providerArgParams
+		i := &includeRouter{providers: ps}
+		var params []*inParam
+		for j := 0; j < arg._type.NumField(); j++ {
+			if params, err = i.parseIn(arg._type.Field(j), []int{j}, ""); err != nil {
+				return
+			}
+			for _, in := range params {
+				if in.parentInType != "" || !inArray(in.inType, []InType{inTypeQuery, inTypeHeader, inTypeCookie}) {
+					return fmt.Errorf("only 'query', 'header' and 'cookie' can be declared in the parameters of the provider, field: %v",
+						in.structField.Name)
+				}
+			}
+			p.params = append(p.params, params...)
+		}
+	}
+	return
+}
+
+// findCycle returns the circular providers in the dependencies, empty if there is no cycle
+func (p *provider) findCycle(path []*provider) string {
+	for k, item := range path {
+		if item == p {
+			var cycle string
+			for _, v := range path[k:] {
+				cycle += fmt.Sprintf("'%v' -> ", v.outType)
+			}
+			return cycle + fmt.Sprintf("'%v'", p.outType)
+		}
+	}
+	path = append(path, p)
+	for _, arg := range p.args {
+		if arg.depend == nil {
+			continue
+		}
+		if cycle := arg.depend.findCycle(path); cycle != "" {
+			return cycle
+		}
+	}
+	return ""
+}
+
+// dependParams returns the parameters of the providers of the dependencies, including the providers depended on,
+// the parameters are copied for the router and bound when the provider is called
+func dependParams(ins []*inParam) (params []*inParam) {
+	seen := map[*provider]struct{}{}
+	var walk func(p *provider)
+	walk = func(p *provider) {
+		if _, ok := seen[p]; ok {
+			return
+		}
+		seen[p] = struct{}{}
+		for _, arg := range p.args {
+			if arg.depend != nil {
+				walk(arg.depend)
+			}
+		}
+		for _, in := range p.params {
+			param := *in
+			param.depend = p
+			params = append(params, &param)
+		}
+	}
+	for _, in := range ins {
+		if in.inType == inTypeDepend {
+			walk(in.depend)
+		}
+	}
+	return
+}
+
+// call It calls the provider, the cleanup is nil if the provider does not return it or returns an error.
+// The error other than *HTTPError is written by the log and responded as a generic 500, so that the details are not exposed
+func (p *provider) call(log Logger, args []reflect.Value) (value reflect.Value, cleanup func(), err error) {
+	rs := p.fn.Call(args)
+	if p.hasErr {
+		if respErr, _ := rs[len(rs)-1].Interface().(error); respErr != nil {
+			if httpErr, ok := respErr.(*HTTPError); ok {
+				return value, nil, httpErr
+			}
+			if log != nil {
+				log.Error("the provider of '%v' failed: %v, pos: %v", p.outType, respErr, p.pos)
+			}
+			return value, nil, NewHTTPError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		}
+	}
+	if p.hasCleanup {
+		cleanup, _ = rs[1].Interface().(func())
+	}
+	return rs[0], cleanup, nil
+}
+
+// singleton returns the value of ScopeSingleton, it is created by the first call
+func (p *provider) singleton(log Logger) (reflect.Value, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.done {
+		return p.value, nil
+	}
+	args := make([]reflect.Value, len(p.args))
+	var err error
+	for k, arg := range p.args {
+		if args[k], err = arg.depend.singleton(log); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	value, cleanup, err := p.call(log, args)
+	if err != nil {
+		return value, err
+	}
+	if cleanup != nil {
+		p.owner.mux.Lock()
+		p.owner.cleanups = append(p.owner.cleanups, cleanup)
+		p.owner.mux.Unlock()
+	}
+	p.value, p.done = value, true
+	return value, nil
+}
+
+// shutdown executes the cleanups of the singletons in reverse order, the singletons are created again by the next request
+func (ps *providers) shutdown() {
+	ps.mux.Lock()
+	cleanups := ps.cleanups
+	ps.cleanups = nil
+	list := ps.list
+	ps.mux.Unlock()
+	for _, p := range list {
+		p.mux.Lock()
+		p.value, p.done = reflect.Value{}, false
+		p.mux.Unlock()
+	}
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
@@ -0,0 +1,157 @@
+package goapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type providerTestDB struct {
+	name string
+}
+
+type providerTestTx struct {
+	db     *providerTestDB
+	tenant string
+}
+
+type providerTestUser struct {
+	ID int `path:"id"`
+	Tx *providerTestTx
+	// the same value of the request
+	Again *providerTestTx
+}
+
+type providerTestLoop struct{}
+
+type providerTestOther struct{}
+
+func TestProvide(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	var dbCount int
+	var trace []string
+	api.Provide(func(ctx *Context, db *providerTestDB, in struct {
+		Tenant string `header:"X-Tenant" desc:"the tenant"`
+		Limit  int    `query:"limit,omitempty" lte:"10"`
+	}) (*providerTestTx, func(), error) {
+		if in.Tenant == "none" {
+			return nil, nil, NewHTTPError(http.StatusForbidden, "no tenant")
+		}
+		if in.Tenant == "down" {
+			return nil, nil, errors.New("dial tcp 10.0.0.1:3306: connection refused")
+		}
+		trace = append(trace, "begin")
+		return &providerTestTx{db: db, tenant: in.Tenant}, func() {
+			trace = append(trace, "end")
+		}, nil
+	})
+	api.Provide(func() *providerTestDB {
+		dbCount++
+		return &providerTestDB{name: "db"}
+	}, ScopeSingleton)
+	Get(api, "/users/{id}", func(ctx *Context, in providerTestUser) (string, error) {
+		trace = append(trace, "handle")
+		if in.Tx != in.Again {
+			t.Error("the value of ScopeRequest should be shared by the request")
+		}
+		return in.Tx.db.name + ":" + in.Tx.tenant, nil
+	})
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tenant string
+		query  string
+		code   int
+		want   string
+		trace  string
+	}{
+		{tenant: "a", code: http.StatusOK, want: "db:a", trace: "begin,handle,end"},
+		{tenant: "b", query: "?limit=20", code: validErrorCode},
+		{tenant: "none", code: http.StatusForbidden, want: "no tenant"},
+		// the details of the error are not exposed
+		{tenant: "down", code: http.StatusInternalServerError, want: http.StatusText(http.StatusInternalServerError)},
+	}
+	for _, tt := range tests {
+		trace = nil
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users/1"+tt.query, nil)
+		r.Header.Set("X-Tenant", tt.tenant)
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%v: got %v %q want %v %q", tt.tenant, w.Code, w.Body.String(), tt.code, tt.want)
+		}
+		if got := strings.Join(trace, ","); got != tt.trace {
+			t.Errorf("%v: got trace %q want %q", tt.tenant, got, tt.trace)
+		}
+	}
+	if dbCount != 1 {
+		t.Errorf("the singleton should be created once, got %v", dbCount)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	for _, want := range []string{`"description":"the tenant","in":"header","name":"X-Tenant"`, `"in":"query","name":"limit"`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("openapi.json should contain %v, got %v", want, w.Body.String())
+		}
+	}
+
+	api = New(false)
+	api.SetLogger(nil)
+	api.Provide(func(other *providerTestOther) *providerTestLoop { return nil })
+	api.Provide(func(loop *providerTestLoop) *providerTestOther { return nil })
+	api.Provide(func(ctx *Context) *providerTestDB { return nil }, ScopeSingleton)
+	api.Provide(func() (*providerTestDB, error) { return nil, nil })
+	api.Provide("tx")
+	_, err = api.Build()
+	for _, want := range []string{
+		"the providers depend on each other circularly",
+		"the provider of ScopeSingleton cannot accept '*goapi.Context'",
+		"the provider of '*goapi.providerTestDB' is duplicated",
+		"the provider must be a function",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Build error should contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestProvideCleanupAfterPanic(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	var cleaned bool
+	api.Provide(func() (*providerTestTx, func()) {
+		return &providerTestTx{}, func() { cleaned = true }
+	})
+	// the panic of the error handler escapes the recovery of the router panic
+	api.HTTPError(func(err error) any {
+		if err.Error() == "failed" {
+			panic(http.ErrAbortHandler)
+		}
+		return err.Error()
+	})
+	Get(api, "/tx", func(ctx *Context, in struct {
+		Tx *providerTestTx
+	}) (string, error) {
+		panic("failed")
+	})
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() {
+			if err := recover(); err != http.ErrAbortHandler {
+				t.Errorf("got the panic %v want %v", err, http.ErrAbortHandler)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tx", nil))
+	}()
+	if !cleaned {
+		t.Error("the cleanup should be executed when a panic escapes")
+	}
+}
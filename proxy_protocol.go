@@ -0,0 +1,245 @@
+package goapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultProxyHeaderTimeout = 10 * time.Second
+	// proxyV1MaxLength is the maximum length of the v1 header including the CRLF
+	proxyV1MaxLength = 107
+)
+
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// ProxyProtocolConfig It is the configuration of the PROXY protocol v1/v2 listener
+type ProxyProtocolConfig struct {
+	// TrustedProxies are the IPs or CIDRs allowed to send the PROXY header, it is required,
+	// otherwise any client could spoof its address. The connections from other sources are served as they are.
+	TrustedProxies []string
+	// HeaderTimeout is the maximum duration for reading the PROXY header, default 10s.
+	HeaderTimeout time.Duration
+}
+
+// NewProxyProtocolListener It returns a listener that parses the PROXY protocol v1/v2 header of the accepted connections,
+// the RemoteAddr of the connections is the proxied client address.
+// The header is optional, and it is read lazily in the connection goroutine, so that Accept is never blocked
+func NewProxyProtocolListener(ln net.Listener, config ProxyProtocolConfig) (net.Listener, error) {
+	if len(config.TrustedProxies) == 0 {
+		return nil, errors.New("the trusted proxies of the PROXY protocol are not set")
+	}
+	trusted, err := parseCIDRs(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	timeout := config.HeaderTimeout
+	if timeout <= 0 {
+		timeout = defaultProxyHeaderTimeout
+	}
+	return &proxyProtocolListener{
+		Listener: ln,
+		trusted:  trusted,
+		timeout:  timeout,
+	}, nil
+}
+
+type proxyProtocolListener struct {
+	net.Listener
+	trusted []*net.IPNet
+	timeout time.Duration
+}
+
+func (p *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := p.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	if !ipInCIDRs(net.ParseIP(host), p.trusted) {
+		return conn, nil
+	}
+	return &proxyProtocolConn{
+		Conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: p.timeout,
+	}, nil
+}
+
+type proxyProtocolConn struct {
+	net.Conn
+	reader     *bufio.Reader
+	timeout    time.Duration
+	once       sync.Once
+	remoteAddr net.Addr
+	localAddr  net.Addr
+	err        error
+}
+
+func (p *proxyProtocolConn) Read(b []byte) (int, error) {
+	p.once.Do(p.readHeader)
+	if p.err != nil {
+		return 0, p.err
+	}
+	return p.reader.Read(b)
+}
+
+func (p *proxyProtocolConn) RemoteAddr() net.Addr {
+	p.once.Do(p.readHeader)
+	if p.remoteAddr != nil {
+		return p.remoteAddr
+	}
+	return p.Conn.RemoteAddr()
+}
+
+func (p *proxyProtocolConn) LocalAddr() net.Addr {
+	p.once.Do(p.readHeader)
+	if p.localAddr != nil {
+		return p.localAddr
+	}
+	return p.Conn.LocalAddr()
+}
+
+func (p *proxyProtocolConn) readHeader() {
+	_ = p.Conn.SetReadDeadline(time.Now().Add(p.timeout))
+	defer func() {
+		_ = p.Conn.SetReadDeadline(time.Time{})
+	}()
+	// the error is returned by the following Read when the connection has less than the signature
+	buf, _ := p.reader.Peek(len(proxyV2Signature))
+	switch {
+	case bytes.HasPrefix(buf, []byte("PROXY ")):
+		p.err = p.readHeaderV1()
+	case bytes.Equal(buf, proxyV2Signature):
+		p.err = p.readHeaderV2()
+	}
+	if p.err != nil {
+		p.err = fmt.Errorf("invalid PROXY protocol header: %w", p.err)
+	}
+}
+
+// readHeaderV1 reads the header like 'PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n'
+func (p *proxyProtocolConn) readHeaderV1() error {
+	var line []byte
+	for {
+		b, err := p.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+		if len(line) >= proxyV1MaxLength {
+			return errors.New("the v1 header is too long")
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return errors.New("the v1 header must end with CRLF")
+	}
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return fmt.Errorf("the v1 header '%v' is malformed", strings.TrimSpace(string(line)))
+	}
+	srcIP, dstIP := net.ParseIP(fields[2]), net.ParseIP(fields[3])
+	srcPort, srcErr := strconv.ParseUint(fields[4], 10, 16)
+	dstPort, dstErr := strconv.ParseUint(fields[5], 10, 16)
+	if srcIP == nil || dstIP == nil || srcErr != nil || dstErr != nil {
+		return fmt.Errorf("the v1 header '%v' is malformed", strings.TrimSpace(string(line)))
+	}
+	p.remoteAddr = &net.TCPAddr{IP: srcIP, Port: int(srcPort)}
+	p.localAddr = &net.TCPAddr{IP: dstIP, Port: int(dstPort)}
+	return nil
+}
+
+// readHeaderV2 reads the binary header, the TLVs are skipped
+func (p *proxyProtocolConn) readHeaderV2() error {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(p.reader, header); err != nil {
+		return err
+	}
+	if header[12]>>4 != 2 {
+		return fmt.Errorf("the v2 version %v is not supported", header[12]>>4)
+	}
+	command := header[12] & 0x0f
+	family := header[13]
+	body := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(p.reader, body); err != nil {
+		return err
+	}
+	switch command {
+	case 0x0:
+		// LOCAL, the connection is established by the proxy itself
+		return nil
+	case 0x1:
+		// PROXY
+	default:
+		return fmt.Errorf("the v2 command %v is not supported", command)
+	}
+	switch family {
+	case 0x11, 0x12:
+		// TCP or UDP over IPv4
+		if len(body) < 12 {
+			return errors.New("the v2 IPv4 addresses are truncated")
+		}
+		p.remoteAddr = &net.TCPAddr{IP: net.IP(body[0:4]), Port: int(binary.BigEndian.Uint16(body[8:10]))}
+		p.localAddr = &net.TCPAddr{IP: net.IP(body[4:8]), Port: int(binary.BigEndian.Uint16(body[10:12]))}
+	case 0x21, 0x22:
+		// TCP or UDP over IPv6
+		if len(body) < 36 {
+			return errors.New("the v2 IPv6 addresses are truncated")
+		}
+		p.remoteAddr = &net.TCPAddr{IP: net.IP(body[0:16]), Port: int(binary.BigEndian.Uint16(body[32:34]))}
+		p.localAddr = &net.TCPAddr{IP: net.IP(body[16:32]), Port: int(binary.BigEndian.Uint16(body[34:36]))}
+	}
+	return nil
+}
+
+// parseCIDRs parses the IPs or CIDRs, an IP is the CIDR with the full mask
+func parseCIDRs(values []string) (cidrs []*net.IPNet, err error) {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP '%v'", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		var cidr *net.IPNet
+		if _, cidr, err = net.ParseCIDR(value); err != nil {
+			return nil, err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return
+}
+
+func ipInCIDRs(ip net.IP, cidrs []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package goapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type proxyProtocolTestRouter struct{}
+
+func (p *proxyProtocolTestRouter) IP(ctx *Context, input struct {
+	router Router `paths:"/ip" methods:"GET"`
+}) string {
+	return ctx.RemoteIP() + "," + ctx.ClientIP()
+}
+
+func runProxyProtocolAPI(t *testing.T, config ProxyProtocolConfig) string {
+	t.Helper()
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&proxyProtocolTestRouter{}, "", false)
+	api.Server(ServerConfig{ProxyProtocol: &config})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.RunListener(ln)
+	}()
+	t.Cleanup(func() {
+		_ = api.Shutdown(context.Background())
+		if err := <-runErr; err != nil {
+			t.Error(err)
+		}
+	})
+	return ln.Addr().String()
+}
+
+func proxyProtocolGet(t *testing.T, addr string, header []byte) (status int, body string) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	request := "GET /ip HTTP/1.1\r\nHost: test\r\nConnection: close\r\n\r\n"
+	if _, err = conn.Write(append(header, request...)); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func proxyV2Header(src, dst net.IP, srcPort, dstPort uint16) []byte {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x21, 0x11, 0, 12)
+	header = append(header, src.To4()...)
+	header = append(header, dst.To4()...)
+	ports := make([]byte, 4)
+	binary.BigEndian.PutUint16(ports[0:2], srcPort)
+	binary.BigEndian.PutUint16(ports[2:4], dstPort)
+	return append(header, ports...)
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	addr := runProxyProtocolAPI(t, ProxyProtocolConfig{TrustedProxies: []string{"127.0.0.1"}})
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{name: "v1", header: []byte("PROXY TCP4 203.0.113.7 10.0.0.1 56324 443\r\n"), want: `"203.0.113.7,203.0.113.7"`},
+		{name: "v1 unknown", header: []byte("PROXY UNKNOWN\r\n"), want: `"127.0.0.1,127.0.0.1"`},
+		{name: "v2", header: proxyV2Header(net.ParseIP("198.51.100.9"), net.ParseIP("10.0.0.1"), 4000, 443),
+			want: `"198.51.100.9,198.51.100.9"`},
+		{name: "no header", want: `"127.0.0.1,127.0.0.1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := proxyProtocolGet(t, addr, tt.header)
+			if status != http.StatusOK || body != tt.want {
+				t.Fatalf("got %v %v want %v", status, body, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolListenerUntrustedSource(t *testing.T) {
+	addr := runProxyProtocolAPI(t, ProxyProtocolConfig{TrustedProxies: []string{"10.0.0.0/8"}})
+	status, body := proxyProtocolGet(t, addr, []byte("PROXY TCP4 203.0.113.7 10.0.0.1 56324 443\r\n"))
+	if status != http.StatusBadRequest || strings.Contains(body, "203.0.113.7") {
+		t.Fatalf("the header from an untrusted source should not be parsed, got %v %v", status, body)
+	}
+}
+
+func TestProxyProtocolRequiresTrustedProxies(t *testing.T) {
+	if _, err := NewProxyProtocolListener(nil, ProxyProtocolConfig{}); err == nil {
+		t.Fatal("the listener without the trusted proxies should be rejected")
+	}
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&proxyProtocolTestRouter{}, "", false)
+	api.Server(ServerConfig{ProxyProtocol: &ProxyProtocolConfig{}})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = api.RunListener(ln); err == nil || !strings.Contains(err.Error(), "trusted proxies") {
+		t.Fatalf("RunListener without the trusted proxies: got %v", err)
+	}
+}
+
+func TestNewProxyProtocolListenerInvalidCIDR(t *testing.T) {
+	if _, err := NewProxyProtocolListener(nil, ProxyProtocolConfig{TrustedProxies: []string{"10.0.0.0/33"}}); err == nil {
+		t.Fatal("an invalid CIDR should be rejected")
+	}
+}
@@ -0,0 +1,227 @@
+package goapi
+
+import (
+	"net"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// handlerSwap It is the http.Handler of an address, the handlerServer is swapped by the next build without locks,
+// so the requests in flight are finished by the old routers
+type handlerSwap struct {
+	server atomic.Value // *handlerServer
+}
+
+func (s *handlerSwap) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.server.Load().(*handlerServer).ServeHTTP(w, r)
+}
+
+// swapHandlers stores the servers into the handlers of the addresses, and returns the handlers
+func (a *API) swapHandlers(servers map[string]*handlerServer) map[string]http.Handler {
+	a.swapMux.Lock()
+	defer a.swapMux.Unlock()
+	if a.swaps == nil {
+		a.swaps = map[string]*handlerSwap{}
+	}
+	handlers := make(map[string]http.Handler, len(servers))
+	for addr, server := range servers {
+		swap, ok := a.swaps[addr]
+		if !ok {
+			swap = &handlerSwap{}
+			a.swaps[addr] = swap
+		}
+		swap.server.Store(server)
+		handlers[addr] = swap
+	}
+	return handlers
+}
+
+// Reload It is to rebuild all the routers, the OpenAPI documents and the swagger, then swap them into the handlers
+// of Build, Handler and Run, the requests in flight are finished by the old routers.
+// The old routers are kept if there are configuration errors, and the *BuildError is returned
+func (a *API) Reload() error {
+	a.updateMux.Lock()
+	defer a.updateMux.Unlock()
+	_, err := a.buildServers()
+	return err
+}
+
+// Update It is to change the routers of a running API by fn, such as IncludeRouter, RemoveRouter and Group,
+// then Reload. All the changes of fn to the routers, the groups and the API, such as Host, HTTPError, Bind,
+// RegisterMiddleware, SetLang and Provide, are rolled back if there are configuration errors.
+// The routers of a running API must be changed only in Update, the new addresses of Bind are not listened
+//
+// example:
+//
+//	err := api.Update(func(api *goapi.API) {
+//		api.RemoveRouter(oldRouter)
+//		api.IncludeRouter(newRouter, "/tenant", true)
+//	})
+func (a *API) Update(fn func(api *API)) error {
+	a.updateMux.Lock()
+	defer a.updateMux.Unlock()
+	snapshot := a.snapshot()
+	fn(a)
+	if _, err := a.buildServers(); err != nil {
+		a.restore(snapshot)
+		return err
+	}
+	return nil
+}
+
+// apiSnapshot It is the configuration of the API saved by Update, it is restored when the changes are rolled back
+type apiSnapshot struct {
+	children             map[*RouterChild]RouterChild
+	groups               map[*RouterGroup]RouterGroup
+	langList             []Lang
+	log                  Logger
+	structTagVariableMap map[string]any
+	structTagVariableErr error
+	serverConfig         ServerConfig
+	binds                []bindInfo
+	startupHooks         []LifespanFunc
+	shutdownHooks        []LifespanFunc
+	generateRequestID    bool
+	useXRequestIDHeader  bool
+	lifespanTimeout      time.Duration
+	remoteIPHeaders      []string
+	backgroundWorkers    int
+	backgroundTimeout    time.Duration
+	trustedProxies       []*net.IPNet
+	isSetTrustedProxies  bool
+	middlewareMap        map[string]HandleFunc
+	middlewareErrs       []error
+	providerList         []*provider
+	providerTypes        map[reflect.Type]*provider
+	providerErrs         []error
+}
+
+func (a *API) snapshot() *apiSnapshot {
+	s := &apiSnapshot{
+		children:             map[*RouterChild]RouterChild{},
+		groups:               map[*RouterGroup]RouterGroup{},
+		langList:             a.langList,
+		log:                  a.log,
+		structTagVariableMap: make(map[string]any, len(a.structTagVariableMap)),
+		structTagVariableErr: a.structTagVariableErr,
+		serverConfig:         a.serverConfig,
+		binds:                a.binds,
+		startupHooks:         a.startupHooks,
+		shutdownHooks:        a.shutdownHooks,
+		generateRequestID:    a.GenerateRequestID,
+		useXRequestIDHeader:  a.UseXRequestIDHeader,
+		lifespanTimeout:      a.LifespanTimeout,
+		remoteIPHeaders:      a.RemoteIPHeaders,
+		backgroundWorkers:    a.BackgroundWorkers,
+		backgroundTimeout:    a.BackgroundTimeout,
+		trustedProxies:       a.trustedProxies,
+		isSetTrustedProxies:  a.isSetTrustedProxies,
+		middlewareMap:        make(map[string]HandleFunc, len(a.middlewareMap)),
+		middlewareErrs:       a.middlewareErrs,
+	}
+	for k, v := range a.structTagVariableMap {
+		s.structTagVariableMap[k] = v
+	}
+	for k, v := range a.middlewareMap {
+		s.middlewareMap[k] = v
+	}
+	s.children[a.RouterChild] = *a.RouterChild
+	a.RouterChild.RouterGroup.snapshot(s)
+	ps := a.providers
+	ps.mux.Lock()
+	s.providerList = ps.list
+	s.providerTypes = make(map[reflect.Type]*provider, len(ps.types))
+	for k, v := range ps.types {
+		s.providerTypes[k] = v
+	}
+	s.providerErrs = ps.errs
+	ps.mux.Unlock()
+	return s
+}
+
+func (a *API) restore(s *apiSnapshot) {
+	for child, saved := range s.children {
+		*child = saved
+	}
+	for group, saved := range s.groups {
+		*group = saved
+	}
+	a.langList = s.langList
+	a.log = s.log
+	a.structTagVariableMap = s.structTagVariableMap
+	a.structTagVariableErr = s.structTagVariableErr
+	a.serverConfig = s.serverConfig
+	a.binds = s.binds
+	a.startupHooks = s.startupHooks
+	a.shutdownHooks = s.shutdownHooks
+	a.GenerateRequestID = s.generateRequestID
+	a.UseXRequestIDHeader = s.useXRequestIDHeader
+	a.LifespanTimeout = s.lifespanTimeout
+	a.RemoteIPHeaders = s.remoteIPHeaders
+	a.BackgroundWorkers = s.backgroundWorkers
+	a.BackgroundTimeout = s.backgroundTimeout
+	a.trustedProxies = s.trustedProxies
+	a.isSetTrustedProxies = s.isSetTrustedProxies
+	a.middlewareMap = s.middlewareMap
+	a.middlewareErrs = s.middlewareErrs
+	ps := a.providers
+	ps.mux.Lock()
+	ps.list = s.providerList
+	ps.types = s.providerTypes
+	ps.errs = s.providerErrs
+	ps.mux.Unlock()
+}
+
+// RemoveRouter It is to remove the routers of IncludeRouter in the group and the subgroups,
+// the routers of Handle, Get, Post and so on are removed by their handler functions,
+// returns false if the router is not found. Use it in API.Update for a running API
+func (r *RouterGroup) RemoveRouter(router any) (ok bool) {
+	handlers := make([]any, 0, len(r.handlers))
+	for _, hd := range r.handlers {
+		switch val := hd.(type) {
+		case *includeRouter:
+			typed, isTyped := val.router.(*typedRouter)
+			if sameRouter(val.router, router) || isTyped && sameRouter(typed.handler.Interface(), router) {
+				ok = true
+				continue
+			}
+		case *RouterGroup:
+			ok = val.RemoveRouter(router) || ok
+		case *RouterChild:
+			ok = val.RemoveRouter(router) || ok
+		}
+		handlers = append(handlers, hd)
+	}
+	r.handlers = handlers
+	return
+}
+
+// snapshot saves the group and the subgroups, the handlers are copied
+func (r *RouterGroup) snapshot(s *apiSnapshot) {
+	saved := *r
+	saved.handlers = append([]any(nil), r.handlers...)
+	s.groups[r] = saved
+	for _, hd := range r.handlers {
+		switch val := hd.(type) {
+		case *RouterGroup:
+			val.snapshot(s)
+		case *RouterChild:
+			s.children[val] = *val
+			val.RouterGroup.snapshot(s)
+		}
+	}
+}
+
+// sameRouter reports whether the routers of IncludeRouter are the same, the functions are compared by the pointer
+func sameRouter(a, b any) bool {
+	aType, bType := reflect.TypeOf(a), reflect.TypeOf(b)
+	if aType == nil || aType != bType {
+		return false
+	}
+	if aType.Kind() == reflect.Func {
+		return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+	}
+	return aType.Comparable() && a == b
+}
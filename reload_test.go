@@ -0,0 +1,164 @@
+package goapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goodluckxu-go/goapi/v2/lang"
+)
+
+type reloadTestRouter struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *reloadTestRouter) Slow(input struct {
+	router Router `paths:"/slow" methods:"GET"`
+}) string {
+	close(r.started)
+	<-r.release
+	return "slow"
+}
+
+type reloadTenantRouter struct{}
+
+func (r *reloadTenantRouter) Tenant(input struct {
+	router Router `paths:"/tenant" methods:"GET"`
+}) string {
+	return "tenant"
+}
+
+func TestUpdate(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	ping := &buildTestRouter{}
+	slow := &reloadTestRouter{started: make(chan struct{}), release: make(chan struct{})}
+	api.IncludeRouter(ping, "", true)
+	api.IncludeRouter(slow, "", true)
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- get("/slow")
+	}()
+	<-slow.started
+	tenant := &reloadTenantRouter{}
+	if err = api.Update(func(api *API) {
+		if !api.RemoveRouter(slow) {
+			t.Error("RemoveRouter should find the router")
+		}
+		api.Group("/v1", true).IncludeRouter(tenant, "", true)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	close(slow.release)
+	if w := <-done; w.Code != http.StatusOK {
+		t.Fatalf("the request in flight: got %v", w.Code)
+	}
+	if w := get("/slow"); w.Code != http.StatusNotFound {
+		t.Fatalf("removed router: got %v", w.Code)
+	}
+	if w := get("/v1/tenant"); w.Code != http.StatusOK {
+		t.Fatalf("added router: got %v", w.Code)
+	}
+	if doc := get("/docs/openapi.json").Body.String(); !strings.Contains(doc, `"/v1/tenant"`) || strings.Contains(doc, `"/slow"`) {
+		t.Fatalf("openapi.json is not regenerated: %v", doc)
+	}
+
+	// the conflict is rolled back, and the old routers are kept
+	if err = api.Update(func(api *API) {
+		api.RemoveRouter(ping)
+		api.IncludeRouter(&buildErrorRouter{}, "", false)
+	}); err == nil {
+		t.Fatal("Update should return the build error")
+	}
+	if w := get("/ping"); w.Code != http.StatusOK {
+		t.Fatalf("the old router: got %v", w.Code)
+	}
+	if err = api.Reload(); err != nil {
+		t.Fatalf("the changes should be rolled back: %v", err)
+	}
+
+	// the configuration other than the routers is also rolled back
+	providerCount := len(api.providers.list)
+	if err = api.Update(func(api *API) {
+		api.Host("api.example.com")
+		api.HTTPError(func(err error) any { return "custom" })
+		api.RegisterMiddleware("audit", func(ctx *Context) { ctx.Next() })
+		api.SetLang(&lang.ZhCn{})
+		api.Provide(func() *reloadTenantRouter { return tenant })
+		api.IncludeRouter(&buildErrorRouter{}, "", false)
+	}); err == nil {
+		t.Fatal("Update should return the build error")
+	}
+	if api.host != "" || api.errorFunc == nil || api.middlewareMap["audit"] != nil || len(api.langList) != 0 ||
+		len(api.providers.list) != providerCount {
+		t.Fatal("the configuration changed by Update should be rolled back")
+	}
+	if w := get("/ping"); w.Code != http.StatusOK {
+		t.Fatalf("the old router after the rollback: got %v", w.Code)
+	}
+}
+
+func TestBuildHandlerServesLaterRouters(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	first, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.IncludeRouter(&reloadTenantRouter{}, "", false)
+	second, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, handler := range []http.Handler{first, second} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tenant", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("the handlers of Build should serve the routers of the last Build, got %v", w.Code)
+		}
+	}
+}
+
+func TestUpdateWhileRunning(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&buildTestRouter{}, "", false)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.RunListener(ln)
+	}()
+	// Update and the build of RunListener are serialized
+	for i := 0; i < 10; i++ {
+		if err = api.Update(func(api *API) {
+			api.Group("/v"+strconv.Itoa(i), false).IncludeRouter(&reloadTenantRouter{}, "", false)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitServing(t, ln.Addr().String())
+	if err = api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-runErr; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -97,3 +97,26 @@ func (w *responseWriter) reset(writer http.ResponseWriter) {
 	w.status = http.StatusOK
 	w.written = false
 }
+
+// headResponseWriter discards the body, it is used to answer HEAD by the GET router.
+// net/http only discards the body of HEAD when the handler writes to its connection directly,
+// the handlers of Build and Handler may also write to the writers unaware of the request method,
+// such as httptest.ResponseRecorder, the buffering or compressing wrappers and the serverless adapters
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Flush implements the http.Flusher interface.
+func (w *headResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,47 @@
+package goapi
+
+// RouteInfo It is the information of a registered router
+type RouteInfo struct {
+	Method      string
+	Path        string // the registered path, such as '/users/{id}'
+	Host        string // the host pattern, see RouterGroup.Host
+	Addr        string // the address of Bind, empty means the address of Run
+	Name        string // the 'name' tag, see API.URL
+	Pos         string // the position of the handler
+	Middlewares int    // the number of middlewares
+	Tags        []string
+	Summary     string
+	Desc        string
+	Deprecated  bool
+	IsDocs      bool // is the router shown in the docs
+	IsSwagger   bool // is the router of the docs
+	IsStatic    bool // is the router of the static files
+	Extensions  Extensions
+}
+
+func newRouteInfo(path *pathInfo, method, p, addr string) RouteInfo {
+	return RouteInfo{
+		Method:      method,
+		Path:        p,
+		Host:        path.host,
+		Addr:        addr,
+		Name:        path.name,
+		Pos:         path.pos,
+		Middlewares: len(path.middlewares),
+		Tags:        path.tags,
+		Summary:     path.summary,
+		Desc:        path.desc,
+		Deprecated:  path.deprecated,
+		IsDocs:      path.isDocs,
+		IsSwagger:   path.isSwagger,
+		IsStatic:    path.inFs != nil,
+		Extensions:  path.extensions,
+	}
+}
+
+// Routes It is to return all the routers of the last Build, Handler or Run in the order of registration
+func (a *API) Routes() []RouteInfo {
+	a.routeMux.RLock()
+	defer a.routeMux.RUnlock()
+	return append([]RouteInfo(nil), a.routes...)
+}
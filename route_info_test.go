@@ -0,0 +1,69 @@
+package goapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type routeInfoTestRouter struct{}
+
+func (r *routeInfoTestRouter) Get(input struct {
+	router Router `paths:"/users/{id},/members/{id}" methods:"GET,HEAD" name:"user.get" tags:"user" summary:"get user" deprecated:"true" x-perm:"user.read"`
+	ID     string `path:"id"`
+}) string {
+	return input.ID
+}
+
+func TestAPIRoutes(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	var route *RouteInfo
+	api.AddMiddleware(func(ctx *Context) {
+		route = ctx.Route()
+		ctx.Next()
+	})
+	api.IncludeRouter(&routeInfoTestRouter{}, "/v1", true)
+	admin := api.Child("/admin", "/admin/docs")
+	admin.IncludeRouter(&buildTestRouter{}, "", false)
+	api.Bind("127.0.0.1:0", admin)
+	if len(api.Routes()) != 0 {
+		t.Fatal("Routes should be empty before Build")
+	}
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := api.Routes()
+	var users, pings, docs int
+	for _, r := range routes {
+		switch {
+		case r.Path == "/v1/users/{id}" || r.Path == "/v1/members/{id}":
+			users++
+			if r.Name != "user.get" || r.Summary != "get user" || !r.Deprecated || len(r.Tags) != 1 ||
+				r.Middlewares != 1 || r.Extensions.Get("x-perm") != "user.read" || r.Pos == "" || r.Addr != "" {
+				t.Errorf("unexpected route %+v", r)
+			}
+		case r.Path == "/admin/ping":
+			pings++
+			if r.Addr != "127.0.0.1:0" || r.Method != http.MethodGet {
+				t.Errorf("unexpected route %+v", r)
+			}
+		case r.IsSwagger:
+			docs++
+		}
+	}
+	if users != 4 || pings != 1 || docs == 0 {
+		t.Fatalf("got %v user routes, %v ping routes and %v docs routes", users, pings, docs)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/members/7", nil))
+	if route == nil || route.Path != "/v1/members/{id}" || route.Method != http.MethodGet || route.Name != "user.get" {
+		t.Fatalf("Context.Route: got %+v", route)
+	}
+	route = nil
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/none", nil))
+	if route != nil {
+		t.Fatalf("Context.Route of not found: got %+v", route)
+	}
+}
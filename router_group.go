@@ -9,19 +9,23 @@ import (
 
 type RouterChildInterface interface {
 	RouterGroupInterface
-	HTTPError(handler func(err error) any)
-	NoRoute(handler func(ctx *Context))
-	NoMethod(handler func(ctx *Context))
 	SetResponseMediaType(mediaTypes ...MediaType)
 }
 
 type RouterGroupInterface interface {
 	AddMiddleware(middlewares ...HandleFunc)
+	UseMiddleware(names ...string)
 	IncludeRouter(router any, prefix string, isDocs bool, middlewares ...HandleFunc)
 	Group(prefix string, isDocs bool) *RouterGroup
 	StaticFile(path, root string)
 	Static(path, root string)
 	StaticFS(path string, fs http.FileSystem)
+	Host(pattern string)
+	HTTPError(handler func(err error) any)
+	NoRoute(handler func(ctx *Context))
+	NoMethod(handler func(ctx *Context))
+	Mount(prefix string, handler http.Handler, docs ...openapi.PathItem)
+	RemoveRouter(router any) bool
 }
 
 type RouterChild struct {
@@ -32,7 +36,11 @@ type RouterChild struct {
 	OpenAPITags            []*openapi.Tag
 	Swagger                swagger.Config
 	RedirectTrailingSlash  bool
+	RedirectFixedPath      bool // redirect to the cleaned path such as '//users/../users' to '/users'
+	CaseInsensitive        bool // match the static parts of the path case-insensitively
 	HandleMethodNotAllowed bool // support http.StatusMethodNotAllowed
+	HandleHead             bool // answer HEAD by the GET router and discard the body
+	HandleOptions          bool // answer OPTIONS with the 'Allow' header of the methods that match the path
 	UseMediaType           bool // use the 'media_type' of the query, if not set, the header key 'Accept' will be used by default
 	// func set
 	noRoute            func(ctx *Context)
@@ -90,13 +98,14 @@ func (r *RouterChild) init() *RouterChild {
 
 func (r *RouterChild) returnObj() (obj returnObjResult, err error) {
 	obj, err = r.RouterGroup.returnObj()
-	if err != nil {
-		return
-	}
 	for _, path := range obj.paths {
 		if path.docsPath == r.docsPath {
 			path.isDocs = path.isDocs && r.IsDocs
 		}
+		// the routers of the children of the API are claimed by the children first
+		if path.child == nil {
+			path.child = r
+		}
 	}
 	docs := obj.docsMap[r.docsPath]
 	docs.isDocs = r.IsDocs
@@ -107,7 +116,11 @@ func (r *RouterChild) returnObj() (obj returnObjResult, err error) {
 	obj.docsMap[r.docsPath] = docs
 	child := obj.childMap[r.childPath]
 	child.redirectTrailingSlash = r.RedirectTrailingSlash
+	child.redirectFixedPath = r.RedirectFixedPath
+	child.caseInsensitive = r.CaseInsensitive
 	child.handleMethodNotAllowed = r.HandleMethodNotAllowed
+	child.handleHead = r.HandleHead
+	child.handleOptions = r.HandleOptions
 	child.useMediaType = r.UseMediaType
 	child.noRoute = r.noRoute
 	child.noMethod = r.noMethod
@@ -124,13 +137,18 @@ func (r *RouterChild) returnObj() (obj returnObjResult, err error) {
 }
 
 type RouterGroup struct {
+	host        string
 	prefix      string
 	groupPrefix string
 	isDocs      bool
 	docsPath    string
 	childPath   string
-	middlewares []HandleFunc
+	middlewares []middlewareInfo
+	providers   *providers // the providers of the API
 	handlers    []any
+	errorFunc   func(err error) any
+	noRoute     func(ctx *Context)
+	noMethod    func(ctx *Context)
 }
 
 // AddMiddleware It is a function for adding middleware
@@ -149,7 +167,8 @@ func (r *RouterGroup) IncludeRouter(router any, prefix string, isDocs bool, midd
 		isDocs:      r.isDocs && isDocs,
 		docsPath:    r.docsPath,
 		childPath:   r.childPath,
-		middlewares: append(r.middlewares, append(r.getMiddlewares(), middlewares...)...),
+		middlewares: append(r.middlewares, append(r.getMiddlewares(), newMiddlewareInfos(middlewares)...)...),
+		providers:   r.providers,
 	})
 }
 
@@ -161,6 +180,8 @@ func (r *RouterGroup) StaticFile(path, root string) {
 		fs:          http.Dir(root),
 		isFile:      true,
 		groupPrefix: r.groupPrefix,
+		docsPath:    r.docsPath,
+		childPath:   r.childPath,
 		middlewares: append(r.middlewares, r.getMiddlewares()...),
 	})
 }
@@ -177,10 +198,42 @@ func (r *RouterGroup) StaticFS(path string, fs http.FileSystem) {
 		path:        path,
 		fs:          fs,
 		groupPrefix: r.groupPrefix,
+		docsPath:    r.docsPath,
+		childPath:   r.childPath,
 		middlewares: append(r.middlewares, r.getMiddlewares()...),
 	})
 }
 
+// DebugPprof Open the system's built-in pprof
+func (r *RouterGroup) DebugPprof() {
+	r.IncludeRouter(debugPprof, "/debug", false)
+}
+
+// Host It is to serve the routers of the group only for the requests of the host, the port of the request is ignored.
+// The host is exact, such as 'api.example.com', or has wildcards, such as '{tenant}.example.com' and
+// '{tenant:[a-z]+}.example.com', the values of the wildcards are in Context.Params and the 'host' tag.
+// The routers of the hosts are matched before the routers without host, the host of the nearest group is used
+func (r *RouterGroup) Host(pattern string) {
+	r.host = pattern
+}
+
+// HTTPError adds handlers for http error of the routers in the group, the handler of the nearest group is used,
+// if no group has the handler, the handler of the child is used
+func (r *RouterGroup) HTTPError(handler func(err error) any) {
+	r.errorFunc = handler
+}
+
+// NoRoute adds handlers for NoRoute of the paths under the prefix of the group, the handler of the nearest group is used
+func (r *RouterGroup) NoRoute(handler func(ctx *Context)) {
+	r.noRoute = handler
+}
+
+// NoMethod sets the handlers called when HandleMethodNotAllowed = true for the paths under the prefix of the group,
+// the handler of the nearest group is used
+func (r *RouterGroup) NoMethod(handler func(ctx *Context)) {
+	r.noMethod = handler
+}
+
 // Group It is an introduction routing group
 func (r *RouterGroup) Group(prefix string, isDocs bool) *RouterGroup {
 	group := &RouterGroup{
@@ -190,40 +243,64 @@ func (r *RouterGroup) Group(prefix string, isDocs bool) *RouterGroup {
 		docsPath:    r.docsPath,
 		childPath:   r.childPath,
 		middlewares: append(r.middlewares, r.getMiddlewares()...),
+		providers:   r.providers,
 	}
 	r.handlers = append(r.handlers, group)
 	return group
 }
 
-func (r *RouterGroup) getMiddlewares() (middlewares []HandleFunc) {
+func (r *RouterGroup) getMiddlewares() (middlewares []middlewareInfo) {
 	for _, hd := range r.handlers {
-		if middleware, ok := hd.(HandleFunc); ok {
-			middlewares = append(middlewares, middleware)
+		switch middleware := hd.(type) {
+		case HandleFunc:
+			middlewares = append(middlewares, middlewareInfo{handle: middleware})
+		case middlewareName:
+			middlewares = append(middlewares, middlewareInfo{name: string(middleware)})
 		}
 	}
 	return middlewares
 }
 
 func (r *RouterGroup) returnObj() (obj returnObjResult, err error) {
+	var errInfo *errorInfo
+	if r.errorFunc != nil {
+		errInfo = &errorInfo{errorFunc: r.errorFunc}
+	}
 	obj.groupMap = map[string]returnObjGroup{
 		r.prefix: {
 			middlewares: r.getMiddlewares(),
+			childPath:   r.childPath,
+			errorInfo:   errInfo,
+			noRoute:     r.noRoute,
+			noMethod:    r.noMethod,
 		},
 	}
 	obj.docsMap = map[string]returnObjDocs{}
 	obj.childMap = map[string]returnObjChild{}
 	obj.mediaTypes = map[MediaType]struct{}{}
 	var childObj returnObjResult
+	var errs []error
 	for _, hd := range r.handlers {
 		if fn, ok := hd.(returnObject); ok {
 			childObj, err = fn.returnObj()
-			if err != nil {
-				return
-			}
+			// the valid routes are still merged, so that all the errors can be collected
+			errs = appendBuildError(errs, err)
 			for k, v := range childObj.groupMap {
 				if k == r.groupPrefix {
 					v.middlewares = append(obj.groupMap[k].middlewares, v.middlewares...)
 				}
+				if old, ok := obj.groupMap[k]; ok {
+					// the groups with the same prefix share the handlers
+					if v.errorInfo == nil {
+						v.errorInfo = old.errorInfo
+					}
+					if v.noRoute == nil {
+						v.noRoute = old.noRoute
+					}
+					if v.noMethod == nil {
+						v.noMethod = old.noMethod
+					}
+				}
 				obj.groupMap[k] = v
 			}
 			for k, v := range childObj.docsMap {
@@ -239,5 +316,20 @@ func (r *RouterGroup) returnObj() (obj returnObjResult, err error) {
 			obj.paths = append(obj.paths, childObj.paths...)
 		}
 	}
+	if errInfo != nil {
+		for _, path := range obj.paths {
+			if path.errorInfo == nil {
+				path.errorInfo = errInfo
+			}
+		}
+	}
+	if r.host != "" {
+		for _, path := range obj.paths {
+			if path.host == "" {
+				path.host = r.host
+			}
+		}
+	}
+	err = newBuildError(errs)
 	return
 }
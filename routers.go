@@ -23,6 +23,7 @@ func (i *IRouters) Child(prefix string, docsPath string) *RouterChild {
 			docsPath:    pathJoin(i.docsPath, docsPath),
 			childPath:   pathJoin(i.childPath, prefix),
 			middlewares: append(i.middlewares, i.getMiddlewares()...),
+			providers:   i.providers,
 		},
 	}
 	child.init()
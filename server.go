@@ -0,0 +1,232 @@
+package goapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ServerConfig It is the configuration of the http.Server used by Run and RunTLS
+type ServerConfig struct {
+	// ReadTimeout is the maximum duration for reading the entire request, including the body.
+	ReadTimeout time.Duration
+	// ReadHeaderTimeout is the amount of time allowed to read request headers.
+	ReadHeaderTimeout time.Duration
+	// WriteTimeout is the maximum duration before timing out writes of the response.
+	WriteTimeout time.Duration
+	// IdleTimeout is the maximum amount of time to wait for the next request when keep-alives are enabled.
+	IdleTimeout time.Duration
+	// MaxHeaderBytes controls the maximum number of bytes the server will read parsing the request header.
+	MaxHeaderBytes int
+	// ShutdownTimeout is the deadline of the graceful shutdown on signals by RunGraceful and RunPrefork, zero means no deadline.
+	ShutdownTimeout time.Duration
+	// ProxyProtocol enables parsing the PROXY protocol v1/v2 header, see NewProxyProtocolListener.
+	ProxyProtocol *ProxyProtocolConfig
+	// TLSConfig is used by RunTLS, such as the min TLS version, cipher suites, client CA pools.
+	// Set GetCertificate to CertReloader.GetCertificate to reload the certificate without a restart.
+	TLSConfig *tls.Config
+	// Configure is called with the built http.Server before serving, other fields can be set here.
+	Configure func(server *http.Server)
+}
+
+// Server It is a function for setting the http.Server configuration used by Run and RunTLS
+func (a *API) Server(config ServerConfig) {
+	a.serverConfig = config
+}
+
+// RunWithConfig is the same as Run, but uses the given http.Server configuration
+func (a *API) RunWithConfig(config ServerConfig, addr ...string) (err error) {
+	a.Server(config)
+	return a.Run(addr...)
+}
+
+// Shutdown gracefully shuts down the server without interrupting any active connections.
+// It stops accepting new connections, waits for active handlers (including SSE streams) to finish,
+// and then returns. If ctx expires first, the remaining connections are closed and the context error is returned.
+// The OnShutdown hooks are executed after the server stops serving.
+// Run and RunTLS return nil after Shutdown has finished.
+func (a *API) Shutdown(ctx context.Context) (err error) {
+	a.serverMux.Lock()
+	if a.inShutdown {
+		a.serverMux.Unlock()
+		<-a.shutdownDone
+		return
+	}
+	a.inShutdown = true
+	servers := make([]*http.Server, 0, len(a.servers))
+	for server := range a.servers {
+		servers = append(servers, server)
+	}
+	a.serverMux.Unlock()
+	defer close(a.shutdownDone)
+	a.writeLogInfo(a.log, "Shutting down")
+	a.writeLogInfo(a.log, "Waiting for connections to close")
+	var wg sync.WaitGroup
+	var errMux sync.Mutex
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			if shutdownErr := server.Shutdown(ctx); shutdownErr != nil {
+				_ = server.Close()
+				errMux.Lock()
+				if err == nil {
+					err = shutdownErr
+				}
+				errMux.Unlock()
+			}
+		}(server)
+	}
+	wg.Wait()
+	if hookErr := a.shutdown(); err == nil {
+		err = hookErr
+	}
+	a.writeLogInfo(a.log, "Finished server process [%v]", ColorDebug(strconv.Itoa(os.Getpid())))
+	return
+}
+
+// shutdownContext returns the context with the deadline of ServerConfig.ShutdownTimeout, used when shutting down on signals
+func (a *API) shutdownContext() (context.Context, context.CancelFunc) {
+	if a.serverConfig.ShutdownTimeout > 0 {
+		return context.WithTimeout(context.Background(), a.serverConfig.ShutdownTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+func (a *API) newServer(handler http.Handler) *http.Server {
+	server := &http.Server{
+		Addr:              a.addr,
+		Handler:           handler,
+		ReadTimeout:       a.serverConfig.ReadTimeout,
+		ReadHeaderTimeout: a.serverConfig.ReadHeaderTimeout,
+		WriteTimeout:      a.serverConfig.WriteTimeout,
+		IdleTimeout:       a.serverConfig.IdleTimeout,
+		MaxHeaderBytes:    a.serverConfig.MaxHeaderBytes,
+	}
+	if a.serverConfig.TLSConfig != nil {
+		server.TLSConfig = a.serverConfig.TLSConfig.Clone()
+	}
+	if a.serverConfig.Configure != nil {
+		a.serverConfig.Configure(server)
+	}
+	return server
+}
+
+// run builds the handlers and executes the startup hooks, then serves on the listener returned by listen,
+// the addresses of Bind are served together by serveFunc.
+// The ready is called once after all the listeners are open, it can be nil
+func (a *API) run(scheme, addr string, listen func() (net.Listener, error),
+	serveFunc func(server *http.Server, ln net.Listener) error, ready func()) (err error) {
+	handlers, err := a.handlerServers()
+	if err != nil {
+		return
+	}
+	if !a.isSetTrustedProxies && a.log != nil {
+		a.log.Warn("all the proxies are trusted by '*Context.ClientIP', the client IP can be spoofed, set them by API.SetTrustedProxies")
+	}
+	if err = a.startup(); err != nil {
+		return
+	}
+	addrList := []string{""}
+	for _, bind := range a.binds {
+		if !inArray(bind.addr, addrList) {
+			addrList = append(addrList, bind.addr)
+		}
+	}
+	listeners, err := a.listenBinds(addrList[1:])
+	if err != nil {
+		_ = a.shutdown()
+		return
+	}
+	ln, err := listen()
+	if err != nil {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+		_ = a.shutdown()
+		return
+	}
+	listeners = append([]net.Listener{ln}, listeners...)
+	defer func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}()
+	if a.serverConfig.ProxyProtocol != nil {
+		for i, l := range listeners {
+			if listeners[i], err = NewProxyProtocolListener(l, *a.serverConfig.ProxyProtocol); err != nil {
+				listeners[i] = l
+				_ = a.shutdown()
+				return
+			}
+		}
+	}
+	if ready != nil {
+		ready()
+	}
+	bindServers := make([]*http.Server, 0, len(listeners)-1)
+	for i := 1; i < len(listeners); i++ {
+		server := a.newServer(handlers[addrList[i]])
+		server.Addr = addrList[i]
+		bindServers = append(bindServers, server)
+		a.writeLogInfo(a.log, "GoAPI running on %v://%v", scheme, addrList[i])
+		go a.serveBind(server, func(server *http.Server, ln net.Listener) func() error {
+			return func() error {
+				return serveFunc(server, ln)
+			}
+		}(server, listeners[i]))
+	}
+	server := a.newServer(handlers[""])
+	a.writeLogInfo(a.log, "GoAPI running on %v://%v (Press CTRL+C to quit)", scheme, addr)
+	return a.serve(server, func() error {
+		return serveFunc(server, listeners[0])
+	}, bindServers)
+}
+
+// serveBind serves the address of Bind, the server is shut down by Shutdown or closed when the main server fails
+func (a *API) serveBind(server *http.Server, serveFunc func() error) {
+	a.serverMux.Lock()
+	if a.inShutdown {
+		a.serverMux.Unlock()
+		return
+	}
+	a.servers[server] = struct{}{}
+	a.serverMux.Unlock()
+	if err := serveFunc(); err != nil && !errors.Is(err, http.ErrServerClosed) && a.log != nil {
+		a.log.Error("serve %v failed: %v", server.Addr, err)
+	}
+	a.serverMux.Lock()
+	delete(a.servers, server)
+	a.serverMux.Unlock()
+}
+
+// serve runs serveFunc on the server, and waits for Shutdown to finish when the server is closed by it.
+// When the server fails, the servers of Bind are closed before the OnShutdown hooks are executed
+func (a *API) serve(server *http.Server, serveFunc func() error, bindServers []*http.Server) (err error) {
+	a.serverMux.Lock()
+	if a.inShutdown {
+		a.serverMux.Unlock()
+		return http.ErrServerClosed
+	}
+	a.servers[server] = struct{}{}
+	a.serverMux.Unlock()
+	err = serveFunc()
+	if errors.Is(err, http.ErrServerClosed) {
+		<-a.shutdownDone
+		return nil
+	}
+	a.serverMux.Lock()
+	delete(a.servers, server)
+	a.serverMux.Unlock()
+	for _, bindServer := range bindServers {
+		_ = bindServer.Close()
+	}
+	_ = a.shutdown()
+	return
+}
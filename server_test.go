@@ -0,0 +1,147 @@
+package goapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type shutdownTestRouter struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *shutdownTestRouter) Slow(input struct {
+	router Router `paths:"/slow" methods:"GET"`
+}) string {
+	close(s.started)
+	<-s.release
+	return "done"
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+func waitServing(t *testing.T, addr string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			_ = conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server on %v did not start", addr)
+}
+
+func TestRunWithConfigAppliesServerSettings(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	configured := make(chan *http.Server, 1)
+	addr := freeAddr(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.RunWithConfig(ServerConfig{
+			ReadHeaderTimeout: 3 * time.Second,
+			IdleTimeout:       5 * time.Second,
+			MaxHeaderBytes:    4096,
+			Configure: func(server *http.Server) {
+				configured <- server
+			},
+		}, addr)
+	}()
+	waitServing(t, addr)
+	got := <-configured
+	if got.ReadHeaderTimeout != 3*time.Second || got.IdleTimeout != 5*time.Second || got.MaxHeaderBytes != 4096 {
+		t.Fatalf("server settings not applied: %+v", got)
+	}
+	if err := api.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run after Shutdown: got %v want nil", err)
+	}
+}
+
+func TestShutdownWaitsForActiveHandlers(t *testing.T) {
+	router := &shutdownTestRouter{started: make(chan struct{}), release: make(chan struct{})}
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(router, "", false)
+	addr := freeAddr(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- api.Run(addr)
+	}()
+	waitServing(t, addr)
+
+	respBody := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			respBody <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respBody <- string(body)
+	}()
+	<-router.started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- api.Shutdown(context.Background())
+	}()
+	select {
+	case <-shutdownErr:
+		t.Fatal("Shutdown returned before the active handler finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(router.release)
+	if err := <-shutdownErr; err != nil {
+		t.Fatal(err)
+	}
+	if body := <-respBody; body != `"done"` {
+		t.Fatalf("in-flight response: got %q", body)
+	}
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run after Shutdown: got %v want nil", err)
+	}
+}
+
+func TestShutdownDeadlineClosesConnections(t *testing.T) {
+	router := &shutdownTestRouter{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(router.release)
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(router, "", false)
+	addr := freeAddr(t)
+	go func() {
+		_ = api.Run(addr)
+	}()
+	waitServing(t, addr)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	<-router.started
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := api.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown error: got %v want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -11,14 +11,16 @@ type staticInfo struct {
 	fs          http.FileSystem
 	isFile      bool
 	groupPrefix string
-	middlewares []HandleFunc
+	docsPath    string
+	childPath   string
+	middlewares []middlewareInfo
 }
 
 func (h *staticInfo) returnObj() (obj returnObjResult, err error) {
-	h.path = pathJoin(h.groupPrefix, h.path)
+	path := pathJoin(h.groupPrefix, h.path)
 	if !h.isFile {
-		if h.path[len(h.path)-1] != '/' {
-			h.path += "/"
+		if path[len(path)-1] != '/' {
+			path += "/"
 		}
 	}
 	fsType := reflect.TypeOf(h.fs)
@@ -27,20 +29,21 @@ func (h *staticInfo) returnObj() (obj returnObjResult, err error) {
 		fsType = fsType.Elem()
 		pos = fmt.Sprintf("%v.(*%v)", fsType.PkgPath(), fsType.Name())
 	}
-	paths := []string{h.path}
+	paths := []string{path}
 	if !h.isFile {
-		h.path += "{filepath:*}"
-		paths = append(paths, h.path)
+		paths = append(paths, path+"{filepath:*}")
 	}
 	obj.paths = append(obj.paths, &pathInfo{
-		paths:       paths,
-		methods:     []string{http.MethodHead, http.MethodGet},
-		pos:         pos,
-		isFile:      h.isFile,
-		inFs:        h.fs,
-		groupPrefix: h.groupPrefix,
-		isDocs:      false,
-		middlewares: h.middlewares,
+		paths:           paths,
+		methods:         []string{http.MethodHead, http.MethodGet},
+		pos:             pos,
+		isFile:          h.isFile,
+		inFs:            h.fs,
+		groupPrefix:     h.groupPrefix,
+		docsPath:        h.docsPath,
+		childPath:       h.childPath,
+		isDocs:          false,
+		middlewareInfos: h.middlewares,
 	})
 	return
 }
@@ -3,6 +3,7 @@ package goapi
 import (
 	"net/http"
 	"reflect"
+	"strings"
 
 	"github.com/goodluckxu-go/goapi/v2/openapi"
 	"github.com/goodluckxu-go/goapi/v2/swagger"
@@ -88,6 +89,7 @@ type inParam struct {
 	structField  reflect.StructField
 	field        *paramField
 	example      any
+	depend       *provider // the provider of inTypeDepend, or the provider declaring the parameter
 }
 
 type outParam struct {
@@ -101,17 +103,24 @@ type outParam struct {
 type pathInfo struct {
 	paths   []string
 	methods []string
+	host    string // the host pattern, see RouterGroup.Host
+	name    string // the name of the router, see API.URL
 	pos     string
 	handle  HandleFunc
 	// call
 	value            reflect.Value
+	receiver         reflect.Value   // the receiver of the struct router
+	method           reflect.Value   // the method of the struct router, called with the receiver
+	bind             *bindPlan       // Pre-built: the binding plan of the inputs
 	inFs             http.FileSystem // file
 	isFile           bool            // file
 	inTypes          []reflect.Type  // func in types
 	inParams         []*inParam
 	outParam         *outParam
-	middlewares      []HandleFunc
-	handlersWithExec []HandleFunc // Pre-built: middlewares + execRouter, reducing allocation for each request
+	middlewares      []HandleFunc     // the middlewares resolved from the middlewareInfos
+	middlewareInfos  []middlewareInfo // the middlewares of the groups and the 'middlewares' tag
+	skipMiddlewares  []string         // the 'skip' tag
+	handlersWithExec []HandleFunc     // Pre-built: middlewares + execRouter, reducing allocation for each request
 	extensions       Extensions
 	existsCtx        bool // exists *goapi.Context
 	// openapi
@@ -121,9 +130,12 @@ type pathInfo struct {
 	deprecated  bool
 	docsPath    string
 	childPath   string
+	child       *RouterChild // the child containing the router, see API.Bind
 	isDocs      bool
 	groupPrefix string
 	isSwagger   bool
+	mountDocs   *openapi.PathItem // the docs of RouterGroup.Mount
+	errorInfo   *errorInfo        // the HTTPError of the nearest group, nil means the HTTPError of the child
 }
 
 type errorInfo struct {
@@ -132,7 +144,11 @@ type errorInfo struct {
 }
 
 type returnObjGroup struct {
-	middlewares []HandleFunc
+	middlewares []middlewareInfo
+	childPath   string
+	errorInfo   *errorInfo
+	noRoute     func(ctx *Context)
+	noMethod    func(ctx *Context)
 }
 
 type returnObjDocs struct {
@@ -145,7 +161,11 @@ type returnObjDocs struct {
 
 type returnObjChild struct {
 	redirectTrailingSlash  bool
+	redirectFixedPath      bool
+	caseInsensitive        bool
 	handleMethodNotAllowed bool
+	handleHead             bool
+	handleOptions          bool
 	useMediaType           bool
 	noRoute                func(ctx *Context)
 	noMethod               func(ctx *Context)
@@ -170,6 +190,38 @@ func (h *HTTPError) Error() string {
 	return h.Message
 }
 
+// BuildError It is all the configuration errors collected by Build
+type BuildError struct {
+	Errors []error
+}
+
+func (b *BuildError) Error() string {
+	msgs := make([]string, 0, len(b.Errors))
+	for _, err := range b.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// appendBuildError appends err to errs, the errors of a BuildError are flattened
+func appendBuildError(errs []error, err error) []error {
+	if err == nil {
+		return errs
+	}
+	if buildErr, ok := err.(*BuildError); ok {
+		return append(errs, buildErr.Errors...)
+	}
+	return append(errs, err)
+}
+
+// newBuildError returns nil when errs is empty
+func newBuildError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &BuildError{Errors: errs}
+}
+
 type LogField struct {
 	Key   string
 	Value any
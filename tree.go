@@ -3,6 +3,7 @@ package goapi
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -90,6 +91,45 @@ type node struct {
 
 	// Wildcard param
 	params []string
+
+	// the constraint of the param wildcard, nil means any value
+	constraint *routeConstraint
+}
+
+// routeConstraint restricts the value of a param wildcard, such as {id:int}, {id:uuid} or {slug:[a-z-]+}
+type routeConstraint struct {
+	// the text after ':' in the wildcard
+	expr string
+
+	// the pattern of the value, it is also the pattern of openapi
+	pattern string
+
+	// the format of openapi
+	format string
+
+	regexp *regexp.Regexp
+}
+
+// routeConstraintTypes are the named constraints, other constraints are regular expressions
+var routeConstraintTypes = map[string]routeConstraint{
+	"int":   {pattern: `^-?[0-9]+$`},
+	"uint":  {pattern: `^[0-9]+$`},
+	"alpha": {pattern: `^[a-zA-Z]+$`},
+	"uuid": {
+		pattern: `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`,
+		format:  "uuid",
+	},
+}
+
+func (c *routeConstraint) match(value string) bool {
+	return c.regexp.MatchString(value)
+}
+
+func (c *routeConstraint) equal(other *routeConstraint) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return c.expr == other.expr
 }
 
 func countParams(path string) uint16 {
@@ -118,19 +158,26 @@ func (n *node) longestCommonPrefix(a, b string) int {
 
 // Search for a wildcard segment and check the name for invalid characters.
 // Returns true as valid and -1 as index, if no wildcard was found.
+// The braces of the constraint are balanced, such as {id:[0-9]{3}}.
 func (n *node) findWildcard(path string) (wildcard string, i int, valid bool) {
 	i = -1
 	valid = true
+	depth := 0
 	for j := 0; j < len(path); j++ {
 		c := path[j]
 		switch c {
 		case '{':
-			i = j
+			if i == -1 {
+				i = j
+			}
+			depth++
 		case '}':
 			if i == -1 {
 				return path[:j+1], i, false
 			}
-			return path[i : j+1], i, valid
+			if depth--; depth == 0 {
+				return path[i : j+1], i, valid
+			}
 		case '/':
 			if i != -1 {
 				valid = false
@@ -142,20 +189,61 @@ func (n *node) findWildcard(path string) (wildcard string, i int, valid bool) {
 
 func (n *node) parseWildcard(wildcard string) (rs string, nType nodeType) {
 	wildcard = wildcard[1 : len(wildcard)-1]
-	rs = strings.TrimSuffix(wildcard, ":*")
+	rs, expr, _ := strings.Cut(wildcard, ":")
 	nType = param
-	if rs != wildcard {
+	if expr == "*" {
 		nType = catchAll
 	}
 	return
 }
 
+// parseConstraint returns the constraint of the param wildcard, nil if there is none
+func (n *node) parseConstraint(wildcard string) (constraint *routeConstraint, err error) {
+	_, expr, found := strings.Cut(wildcard[1:len(wildcard)-1], ":")
+	if !found || expr == "*" {
+		return
+	}
+	if expr == "" {
+		err = errors.New("the constraint of wildcard '" + wildcard + "' cannot be empty")
+		return
+	}
+	c, ok := routeConstraintTypes[expr]
+	if !ok {
+		c.pattern = "^(?:" + expr + ")$"
+	}
+	c.expr = expr
+	if c.regexp, err = regexp.Compile(c.pattern); err != nil {
+		err = fmt.Errorf("the constraint of wildcard '%v' is invalid: %v", wildcard, err)
+		return
+	}
+	constraint = &c
+	return
+}
+
+// wildcardChildren returns the wildcard children at the end of the child nodes
+func (n *node) wildcardChildren() []*node {
+	i := len(n.children)
+	for i > 0 && n.children[i-1].nType != static {
+		i--
+	}
+	return n.children[i:]
+}
+
 // addChild will add a child node, keeping wildcardChild at the end
 func (n *node) addChild(child *node) {
-	if len(n.children) > 0 && n.children[len(n.children)-1].nType != static {
-		n.children = append(n.children[:len(n.children)-1], child, n.children[len(n.children)-1])
-	} else {
-		n.children = append(n.children, child)
+	i := len(n.children) - len(n.wildcardChildren())
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = child
+}
+
+// addWildcardChild will add a wildcard child node, the constrained params are matched before the plain param
+func (n *node) addWildcardChild(child *node) {
+	n.children = append(n.children, child)
+	n.isWildcard = true
+	last := len(n.children) - 1
+	if child.constraint != nil && last > 0 && n.children[last-1].nType == param && n.children[last-1].constraint == nil {
+		n.children[last-1], n.children[last] = n.children[last], n.children[last-1]
 	}
 }
 
@@ -232,20 +320,24 @@ walk:
 				n = child
 			} else if n.isWildcard {
 				// inserting a wildcard node, need to check if it conflicts with the existing wildcard
-				wildcard := path[:strings.IndexByte(path, '}')+1]
+				wildcard, _, _ := n.findWildcard(path)
 				paramStr, nType := n.parseWildcard(wildcard)
-				params = append(params, paramStr)
-				n = n.children[len(n.children)-1]
-				n.priority++
-				path = path[len(wildcard):]
-
-				if n.nType == catchAll || nType == catchAll {
-					err = errors.New("the 'catch-all' wildcard can only exist once, has '" + wildcard +
-						"' in path '" + fullPath + "'")
-					return
+				constraint, _ := n.parseConstraint(wildcard)
+				for _, child := range n.wildcardChildren() {
+					if child.nType == catchAll || nType == catchAll {
+						err = errors.New("the 'catch-all' wildcard can only exist once, has '" + wildcard +
+							"' in path '" + fullPath + "'")
+						return
+					}
+					// the params with the same constraint share the node
+					if child.constraint.equal(constraint) {
+						params = append(params, paramStr)
+						n = child
+						n.priority++
+						path = path[len(wildcard):]
+						continue walk
+					}
 				}
-
-				continue walk
 			}
 
 			err = n.insertChild(path, fullPath, params, handler)
@@ -297,7 +389,17 @@ func (n *node) insertChild(path string, fullPath string, params []string, handle
 		}
 
 		paramStr, nType := n.parseWildcard(wildcard)
+		if paramStr == "" || strings.ContainsAny(paramStr, "{}") {
+			err = errors.New("wildcards must be named with a valid name, has '" + wildcard + "' in path '" +
+				fullPath + "'")
+			return
+		}
 		params = append(params, paramStr)
+		var constraint *routeConstraint
+		if constraint, err = n.parseConstraint(wildcard); err != nil {
+			err = errors.New(err.Error() + " in path '" + fullPath + "'")
+			return
+		}
 
 		// if the path doesn't end with the wildcard, then there
 		// will be another subpath starting with '/'
@@ -313,12 +415,12 @@ func (n *node) insertChild(path string, fullPath string, params []string, handle
 			// param
 			path = path[len(wildcard):]
 			child := &node{
-				nType:    nType,
-				priority: 1,
-				indices:  []byte{path[0]},
+				nType:      nType,
+				priority:   1,
+				indices:    []byte{path[0]},
+				constraint: constraint,
 			}
-			n.addChild(child)
-			n.isWildcard = true
+			n.addWildcardChild(child)
 			n = child
 			// surplus path
 			child = &node{
@@ -332,14 +434,14 @@ func (n *node) insertChild(path string, fullPath string, params []string, handle
 
 		// The last one is the wildcard node
 		child := &node{
-			nType:    nType,
-			handler:  handler,
-			fullPath: fullPath,
-			params:   params,
-			priority: 1,
+			nType:      nType,
+			handler:    handler,
+			fullPath:   fullPath,
+			params:     params,
+			priority:   1,
+			constraint: constraint,
 		}
-		n.addChild(child)
-		n.isWildcard = true
+		n.addWildcardChild(child)
 		return
 	}
 	// If no wildcard was found, simply insert the path and handle
@@ -414,6 +516,13 @@ walk:
 			i := 0
 			for ; i < len(path) && path[i] != '/'; i++ {
 			}
+			if n.constraint != nil && !n.constraint.match(path[:i]) {
+				// The constraint is not satisfied, roll back to the other routes
+				if n, path = n.rollback(params, skippedNodes); n == nil {
+					return
+				}
+				continue walk
+			}
 			*params = append(*params, Param{Value: path[:i]})
 			path = path[i:]
 			isMatch = true
@@ -455,13 +564,7 @@ walk:
 			for i, c := range n.indices {
 				if c == path[0] {
 					if n.isWildcard {
-						skippedParam := make(Params, len(*params))
-						copy(skippedParam, *params)
-						*skippedNodes = append(*skippedNodes, skippedNode{
-							path:   path,
-							node:   n.children[len(n.children)-1],
-							params: &skippedParam,
-						})
+						n.skipWildcards(n.wildcardChildren(), path, params, skippedNodes)
 					}
 					n = n.children[i]
 					continue walk
@@ -470,31 +573,52 @@ walk:
 
 			// If the static child node fails to match, the wildcard child node will be matched
 			if n.isWildcard {
-				n = n.children[len(n.children)-1]
+				wildcards := n.wildcardChildren()
+				n.skipWildcards(wildcards[1:], path, params, skippedNodes)
+				n = wildcards[0]
 				continue walk
 			}
 		}
 
 		// When the matching is completed, return the matching value
-		if path == "" {
+		if path == "" && (n.handler != nil || len(*skippedNodes) == 0) {
 			n.returnValue(params, &value)
 			return
 		}
 		// Handle the matching of the rollback
 		// If the rollback fails, return
-		if len(*skippedNodes) == 0 {
+		if n, path = n.rollback(params, skippedNodes); n == nil {
 			return
 		}
-		length := len(*skippedNodes)
-		skipped := (*skippedNodes)[length-1]
-		*skippedNodes = (*skippedNodes)[:length-1]
-		n = skipped.node
-		path = skipped.path
-		*params = make(Params, len(*skipped.params))
-		copy(*params, *skipped.params)
 	}
 }
 
+// skipWildcards adds the wildcard child nodes to skippedNodes, the first one will be rolled back first
+func (n *node) skipWildcards(wildcards []*node, path string, params *Params, skippedNodes *[]skippedNode) {
+	for i := len(wildcards) - 1; i >= 0; i-- {
+		skippedParam := make(Params, len(*params))
+		copy(skippedParam, *params)
+		*skippedNodes = append(*skippedNodes, skippedNode{
+			path:   path,
+			node:   wildcards[i],
+			params: &skippedParam,
+		})
+	}
+}
+
+// rollback returns the last skipped node and its path, the node is nil if there is none
+func (n *node) rollback(params *Params, skippedNodes *[]skippedNode) (*node, string) {
+	length := len(*skippedNodes)
+	if length == 0 {
+		return nil, ""
+	}
+	skipped := (*skippedNodes)[length-1]
+	*skippedNodes = (*skippedNodes)[:length-1]
+	*params = make(Params, len(*skipped.params))
+	copy(*params, *skipped.params)
+	return skipped.node, skipped.path
+}
+
 func (n *node) returnValue(params *Params, valuePtr *nodeValue) {
 	// The number of matching wildcards is incorrect
 	if len(n.params) != len(*params) {
@@ -507,3 +631,42 @@ func (n *node) returnValue(params *Params, valuePtr *nodeValue) {
 		(*params)[key].Key = n.params[key]
 	}
 }
+
+// findCaseInsensitivePath returns the path of the router that matches the path case-insensitively,
+// the static parts are replaced with the registered ones and the values of the wildcards are kept
+func (n *node) findCaseInsensitivePath(path string) (string, bool) {
+	ciPath, ok := n.findCaseInsensitivePathRec(path, make([]byte, 0, len(path)+1))
+	return string(ciPath), ok
+}
+
+func (n *node) findCaseInsensitivePathRec(path string, ciPath []byte) ([]byte, bool) {
+	switch n.nType {
+	case catchAll:
+		return append(ciPath, path...), n.handler != nil
+	case param:
+		i := 0
+		for ; i < len(path) && path[i] != '/'; i++ {
+		}
+		if n.constraint != nil && !n.constraint.match(path[:i]) {
+			return nil, false
+		}
+		ciPath = append(ciPath, path[:i]...)
+		path = path[i:]
+	default:
+		if len(path) < len(n.path) || !strings.EqualFold(path[:len(n.path)], n.path) {
+			return nil, false
+		}
+		ciPath = append(ciPath, n.path...)
+		path = path[len(n.path):]
+	}
+	if path == "" {
+		return ciPath, n.handler != nil
+	}
+	// The static child nodes are in front of the wildcard child nodes, so they are matched first
+	for _, child := range n.children {
+		if rs, ok := child.findCaseInsensitivePathRec(path, ciPath); ok {
+			return rs, true
+		}
+	}
+	return nil, false
+}
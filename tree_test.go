@@ -575,3 +575,143 @@ func TestNode_ReturnValue_ParamCountMismatch(t *testing.T) {
 		t.Error("expected nil handler when param count mismatches")
 	}
 }
+
+func TestNode_ParseConstraint(t *testing.T) {
+	n := &node{}
+	tests := []struct {
+		wildcard string
+		expr     string
+		format   string
+		wantErr  bool
+	}{
+		{wildcard: "{id}"},
+		{wildcard: "{path:*}"},
+		{wildcard: "{id:int}", expr: "int"},
+		{wildcard: "{id:uuid}", expr: "uuid", format: "uuid"},
+		{wildcard: "{code:[0-9]{3}}", expr: "[0-9]{3}"},
+		{wildcard: "{id:}", wantErr: true},
+		{wildcard: "{id:[0-9}", wantErr: true},
+	}
+	for _, tt := range tests {
+		c, err := n.parseConstraint(tt.wildcard)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseConstraint(%q) error: %v", tt.wildcard, err)
+			continue
+		}
+		if tt.expr == "" {
+			if c != nil && !tt.wantErr {
+				t.Errorf("parseConstraint(%q) = %v, want nil", tt.wildcard, c.expr)
+			}
+			continue
+		}
+		if c == nil || c.expr != tt.expr || c.format != tt.format {
+			t.Errorf("parseConstraint(%q) = %+v, want expr %q format %q", tt.wildcard, c, tt.expr, tt.format)
+		}
+	}
+}
+
+func TestNode_GetValue_Constraint(t *testing.T) {
+	n := &node{}
+	routes := []string{
+		"/users/{id:int}",
+		"/users/{id:uuid}/posts",
+		"/users/me",
+		"/users/{slug:[a-z-]+}",
+		"/users/{name}/profile",
+		"/codes/{code:[0-9]{3}}",
+		"/years/{year:[0-9]{2,4}}",
+	}
+	for _, r := range routes {
+		if err := n.addRoute(r, fakeHandler); err != nil {
+			t.Fatalf("unexpected error adding %q: %v", r, err)
+		}
+	}
+	tests := []struct {
+		path     string
+		fullPath string
+		param    string
+	}{
+		{path: "/users/42", fullPath: "/users/{id:int}", param: "42"},
+		{path: "/users/-7", fullPath: "/users/{id:int}", param: "-7"},
+		{path: "/users/me", fullPath: "/users/me"},
+		{path: "/users/mel", fullPath: "/users/{slug:[a-z-]+}", param: "mel"},
+		{path: "/users/foo-bar", fullPath: "/users/{slug:[a-z-]+}", param: "foo-bar"},
+		{path: "/users/0b7c8a6e-1f2d-4e3a-9b4c-5d6e7f8a9b0c/posts", fullPath: "/users/{id:uuid}/posts",
+			param: "0b7c8a6e-1f2d-4e3a-9b4c-5d6e7f8a9b0c"},
+		{path: "/users/Foo1/profile", fullPath: "/users/{name}/profile", param: "Foo1"},
+		{path: "/users/42/profile", fullPath: "/users/{name}/profile", param: "42"},
+		{path: "/users/Foo1"},
+		{path: "/users/42/posts"},
+		{path: "/codes/200", fullPath: "/codes/{code:[0-9]{3}}", param: "200"},
+		{path: "/codes/2000"},
+		{path: "/years/2024", fullPath: "/years/{year:[0-9]{2,4}}", param: "2024"},
+		{path: "/years/20245"},
+	}
+	for _, tt := range tests {
+		*params = (*params)[:0]
+		*skippedNodes = (*skippedNodes)[:0]
+		val := n.getValue(tt.path, params, skippedNodes)
+		if tt.fullPath == "" {
+			if val.handler != nil {
+				t.Errorf("path %q: expected no handler, got %q", tt.path, val.fullPath)
+			}
+			continue
+		}
+		if val.handler == nil || val.fullPath != tt.fullPath {
+			t.Errorf("path %q: expected fullPath=%q, got %q", tt.path, tt.fullPath, val.fullPath)
+			continue
+		}
+		if tt.param != "" && (len(*params) != 1 || (*params)[0].Value != tt.param) {
+			t.Errorf("path %q: expected param %q, got %v", tt.path, tt.param, *params)
+		}
+	}
+}
+
+func TestNode_AddRoute_ConstraintConflict(t *testing.T) {
+	n := &node{}
+	if err := n.addRoute("/files/{id:int}", fakeHandler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := n.addRoute("/files/{path:*}", fakeHandler); err == nil {
+		t.Fatal("expected error for catch-all wildcard with a constrained param")
+	}
+	if err := n.addRoute("/files/{num:int}", fakeHandler); err == nil {
+		t.Fatal("expected error for duplicate constrained route")
+	}
+	if err := n.addRoute("/bad/{id:[0-9}", fakeHandler); err == nil {
+		t.Fatal("expected error for invalid regular expression")
+	}
+}
+
+func TestNode_FindCaseInsensitivePath(t *testing.T) {
+	n := &node{}
+	routes := []string{
+		"/users/{id:int}",
+		"/users/me",
+		"/Users/{name}/Profile",
+		"/files/{path:*}",
+	}
+	for _, r := range routes {
+		if err := n.addRoute(r, fakeHandler); err != nil {
+			t.Fatalf("unexpected error adding %q: %v", r, err)
+		}
+	}
+	tests := []struct {
+		path  string
+		want  string
+		found bool
+	}{
+		{path: "/USERS/42", want: "/users/42", found: true},
+		{path: "/Users/ME", want: "/users/me", found: true},
+		{path: "/users/Tom/profile", want: "/Users/Tom/Profile", found: true},
+		{path: "/FILES/A/b.txt", want: "/files/A/b.txt", found: true},
+		{path: "/USERS", found: false},
+		{path: "/orders/1", found: false},
+	}
+	for _, tt := range tests {
+		got, found := n.findCaseInsensitivePath(tt.path)
+		if found != tt.found || (found && got != tt.want) {
+			t.Errorf("findCaseInsensitivePath(%q) = %q, %v, want %q, %v", tt.path, got, found, tt.want, tt.found)
+		}
+	}
+}
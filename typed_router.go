@@ -0,0 +1,165 @@
+package goapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// typedRouter It is the router of Handle, the method and the path are used as they are,
+// the tag of the options replaces the other tags of the 'goapi.Router' parameter
+type typedRouter struct {
+	handler reflect.Value
+	method  string
+	path    string
+	tag     reflect.StructTag
+}
+
+// RouteOption It is the option of the routers of Handle, Get, Post, Put, Patch and Delete
+type RouteOption func(opts *routeOptions)
+
+type routeOptions struct {
+	summary     string
+	desc        string
+	name        string
+	deprecated  bool
+	tags        []string
+	middlewares []string
+	skips       []string
+	isDocs      bool
+}
+
+// WithSummary It is the 'summary' tag of the router
+func WithSummary(summary string) RouteOption {
+	return func(opts *routeOptions) {
+		opts.summary = summary
+	}
+}
+
+// WithDesc It is the 'desc' tag of the router
+func WithDesc(desc string) RouteOption {
+	return func(opts *routeOptions) {
+		opts.desc = desc
+	}
+}
+
+// WithName It is the 'name' tag of the router, see API.URL
+func WithName(name string) RouteOption {
+	return func(opts *routeOptions) {
+		opts.name = name
+	}
+}
+
+// WithDeprecated It is the 'deprecated' tag of the router
+func WithDeprecated() RouteOption {
+	return func(opts *routeOptions) {
+		opts.deprecated = true
+	}
+}
+
+// WithTags It is the 'tags' tag of the router
+func WithTags(tags ...string) RouteOption {
+	return func(opts *routeOptions) {
+		opts.tags = append(opts.tags, tags...)
+	}
+}
+
+// WithMiddlewares It is the 'middlewares' tag of the router, see API.RegisterMiddleware
+func WithMiddlewares(names ...string) RouteOption {
+	return func(opts *routeOptions) {
+		opts.middlewares = append(opts.middlewares, names...)
+	}
+}
+
+// WithSkip It is the 'skip' tag of the router, see API.RegisterMiddleware
+func WithSkip(names ...string) RouteOption {
+	return func(opts *routeOptions) {
+		opts.skips = append(opts.skips, names...)
+	}
+}
+
+// WithDocs It is whether the router is shown in the docs, default true, the router of the group without docs is not shown
+func WithDocs(isDocs bool) RouteOption {
+	return func(opts *routeOptions) {
+		opts.isDocs = isDocs
+	}
+}
+
+// tag returns the tag of 'goapi.Router' except 'paths' and 'methods'
+func (o *routeOptions) tag() reflect.StructTag {
+	items := [][2]string{
+		{tagSummary, o.summary},
+		{tagDesc, o.desc},
+		{tagName, o.name},
+		{tagTags, strings.Join(o.tags, ",")},
+		{tagMiddle, strings.Join(o.middlewares, ",")},
+		{tagSkip, strings.Join(o.skips, ",")},
+	}
+	if o.deprecated {
+		items = append(items, [2]string{tagDeprecated, "true"})
+	}
+	var tags []string
+	for _, item := range items {
+		if item[1] != "" {
+			tags = append(tags, fmt.Sprintf("%v:%v", item[0], strconv.Quote(item[1])))
+		}
+	}
+	return reflect.StructTag(strings.Join(tags, " "))
+}
+
+// Handle It is to register a router of the group with the types of the input and output checked at compile time.
+// The In is a structure parsed like the input of IncludeRouter without the 'goapi.Router' parameter,
+// and the Out is returned like the first result of IncludeRouter, they are shown in the docs of the group.
+// The method is one HTTP method and the path is one path, they are not split by ',' like the tags.
+// The router is removed by RemoveRouter with the handler, the closures of the same function literal are the same
+//
+// example:
+//
+//	goapi.Handle(api, http.MethodGet, "/users/{id}", func(ctx *goapi.Context, in GetUser) (*User, error) {
+//		return findUser(in.ID)
+//	}, goapi.WithSummary("get user"), goapi.WithTags("user"))
+func Handle[In, Out any](group RouterGroupInterface, method, path string, handler func(ctx *Context, in In) (Out, error),
+	options ...RouteOption) {
+	opts := &routeOptions{isDocs: true}
+	for _, option := range options {
+		option(opts)
+	}
+	group.IncludeRouter(&typedRouter{
+		handler: reflect.ValueOf(handler),
+		method:  method,
+		path:    path,
+		tag:     opts.tag(),
+	}, "", opts.isDocs)
+}
+
+// Get It is to register a GET router of the group, see Handle
+func Get[In, Out any](group RouterGroupInterface, path string, handler func(ctx *Context, in In) (Out, error),
+	options ...RouteOption) {
+	Handle(group, http.MethodGet, path, handler, options...)
+}
+
+// Post It is to register a POST router of the group, see Handle
+func Post[In, Out any](group RouterGroupInterface, path string, handler func(ctx *Context, in In) (Out, error),
+	options ...RouteOption) {
+	Handle(group, http.MethodPost, path, handler, options...)
+}
+
+// Put It is to register a PUT router of the group, see Handle
+func Put[In, Out any](group RouterGroupInterface, path string, handler func(ctx *Context, in In) (Out, error),
+	options ...RouteOption) {
+	Handle(group, http.MethodPut, path, handler, options...)
+}
+
+// Patch It is to register a PATCH router of the group, see Handle
+func Patch[In, Out any](group RouterGroupInterface, path string, handler func(ctx *Context, in In) (Out, error),
+	options ...RouteOption) {
+	Handle(group, http.MethodPatch, path, handler, options...)
+}
+
+// Delete It is to register a DELETE router of the group, see Handle
+func Delete[In, Out any](group RouterGroupInterface, path string, handler func(ctx *Context, in In) (Out, error),
+	options ...RouteOption) {
+	Handle(group, http.MethodDelete, path, handler, options...)
+}
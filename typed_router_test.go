@@ -0,0 +1,162 @@
+package goapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type typedGetUser struct {
+	ID int `path:"id"`
+}
+
+type typedCreateUser struct {
+	Body *typedUser `body:"json"`
+}
+
+type typedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestTypedRouter(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	group := api.Group("/v1", true)
+	Get(group, "/users/{id}", func(ctx *Context, in typedGetUser) (*typedUser, error) {
+		if in.ID < 0 {
+			return nil, NewHTTPError(http.StatusNotFound, "user not found")
+		}
+		return &typedUser{ID: in.ID, Name: "tom"}, nil
+	}, WithSummary("get user"), WithTags("user"), WithName("user.get"))
+	Post(api, "/users", func(ctx *Context, in typedCreateUser) (*typedUser, error) {
+		return in.Body, nil
+	}, WithDeprecated())
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+		want   string
+	}{
+		{method: http.MethodGet, path: "/v1/users/7", code: http.StatusOK, want: `{"id":7,"name":"tom"}`},
+		{method: http.MethodGet, path: "/v1/users/-1", code: http.StatusNotFound, want: "user not found"},
+		{method: http.MethodPost, path: "/users", body: `{"id":1,"name":"ann"}`, code: http.StatusOK, want: `{"id":1,"name":"ann"}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%v %v: got %v %q want %v %q", tt.method, tt.path, w.Code, w.Body.String(), tt.code, tt.want)
+		}
+	}
+	if u, err := api.URL("user.get", "id", "3"); err != nil || u != "/v1/users/3" {
+		t.Errorf("URL: got %q, %v", u, err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	for _, want := range []string{`"/v1/users/{id}":{`, `"summary":"get user"`, `"deprecated":true`, `"name":{"type":"string"}`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("openapi.json should contain %v, got %v", want, w.Body.String())
+		}
+	}
+
+	api = New(false)
+	Get(api, "/ping", func(ctx *Context, in struct {
+		router Router `paths:"/ping" methods:"GET"`
+	}) (string, error) {
+		return "pong", nil
+	})
+	if _, err = api.Build(); err == nil || !strings.Contains(err.Error(), "cannot be used with the generic routers") {
+		t.Fatalf("Build should fail with the 'goapi.Router' parameter, got %v", err)
+	}
+}
+
+func TestTypedRouterDocsAndRemove(t *testing.T) {
+	api := New(true)
+	api.SetLogger(nil)
+	getUser := func(ctx *Context, in typedGetUser) (*typedUser, error) {
+		return &typedUser{ID: in.ID}, nil
+	}
+	Get(api, "/users/{id}", getUser)
+	Get(api, "/internal/users/{id}", func(ctx *Context, in typedGetUser) (*typedUser, error) {
+		return &typedUser{ID: in.ID}, nil
+	}, WithDocs(false))
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	doc := get("/docs/openapi.json").Body.String()
+	if !strings.Contains(doc, `"/users/{id}"`) || strings.Contains(doc, `"/internal/users/{id}"`) {
+		t.Fatalf("the router of WithDocs(false) should not be shown in the docs, got %v", doc)
+	}
+	if w := get("/internal/users/1"); w.Code != http.StatusOK {
+		t.Fatalf("the router of WithDocs(false) should be served, got %v", w.Code)
+	}
+	if err = api.Update(func(api *API) {
+		if !api.RemoveRouter(getUser) {
+			t.Error("RemoveRouter should find the router by the handler")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if w := get("/users/1"); w.Code != http.StatusNotFound {
+		t.Fatalf("the removed router: got %v", w.Code)
+	}
+	if w := get("/internal/users/1"); w.Code != http.StatusOK {
+		t.Fatalf("the other router should be kept, got %v", w.Code)
+	}
+}
+
+func TestTypedRouterSingleRoute(t *testing.T) {
+	getUser := func(ctx *Context, in typedGetUser) (*typedUser, error) {
+		return &typedUser{ID: in.ID}, nil
+	}
+	api := New(false)
+	api.SetLogger(nil)
+	Get(api, "/codes/{id:[0-9]{1,3}}", getUser)
+	Handle(api, "put", "/users/{id}", getUser)
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/codes/12", nil),
+		httptest.NewRequest(http.MethodPut, "/users/1", nil),
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("%v %v: got %v want %v", r.Method, r.URL.Path, w.Code, http.StatusOK)
+		}
+	}
+
+	api = New(false)
+	api.SetLogger(nil)
+	// the method and the path are not split like the tags
+	Get(api, "/a,/b", getUser)
+	Handle(api, "GET,POST", "/c", getUser)
+	Get(api, "", getUser)
+	_, err = api.Build()
+	for _, want := range []string{
+		"the path '/a,/b' must be one path",
+		"the method 'GET,POST' must be one of",
+		"the path of the router must be set",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Build error should contain %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package goapi
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL It is to return the path of the router with the 'name' tag, the wildcards are filled by params.
+// The params are key-value pairs, and the routers are available after Build, Handler or Run
+//
+// example:
+//
+//	type User struct{}
+//
+//	func (*User) Get(input struct {
+//		router goapi.Router `paths:"/users/{id}" methods:"GET" name:"user.get"`
+//		ID     int          `path:"id"`
+//	}) {
+//	}
+//
+//	api.URL("user.get", "id", "7") // /users/7
+func (a *API) URL(name string, params ...string) (string, error) {
+	a.routeMux.RLock()
+	path := a.namedPaths[name]
+	a.routeMux.RUnlock()
+	return buildURL(name, path, params)
+}
+
+// buildURL fills the wildcards of the first path of the router, the values are escaped
+func buildURL(name string, path *pathInfo, params []string) (string, error) {
+	if path == nil {
+		return "", fmt.Errorf("the router name '%v' does not exist", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("the params of the router '%v' must be key-value pairs", name)
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+	n := &node{}
+	var sb strings.Builder
+	rest := path.paths[0]
+	for {
+		wildcard, i, _ := n.findWildcard(rest)
+		if i < 0 || len(wildcard) < 3 {
+			break
+		}
+		sb.WriteString(rest[:i])
+		rest = rest[i+len(wildcard):]
+		key, nType := n.parseWildcard(wildcard)
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("the param '%v' of the router '%v' is missing", key, name)
+		}
+		delete(values, key)
+		if nType == catchAll {
+			segments := strings.Split(value, "/")
+			for k, segment := range segments {
+				segments[k] = url.PathEscape(segment)
+			}
+			sb.WriteString(strings.Join(segments, "/"))
+			continue
+		}
+		if constraint, _ := n.parseConstraint(wildcard); constraint != nil && !constraint.match(value) {
+			return "", fmt.Errorf("the param '%v' of the router '%v' does not match '%v'", key, name, constraint.expr)
+		}
+		sb.WriteString(url.PathEscape(value))
+	}
+	for key := range values {
+		return "", fmt.Errorf("the param '%v' does not exist in the router '%v'", key, name)
+	}
+	sb.WriteString(rest)
+	return sb.String(), nil
+}
@@ -0,0 +1,78 @@
+package goapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type urlTestRouter struct{}
+
+func (u *urlTestRouter) Get(ctx *Context, input struct {
+	router Router `paths:"/users/{id:int}" methods:"GET" name:"user.get"`
+	ID     int    `path:"id"`
+}) {
+	location, err := ctx.URLFor("file.get", "path", "a b/c.txt")
+	if err != nil {
+		ctx.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ctx.Writer.Header().Set("Location", location)
+}
+
+func (u *urlTestRouter) File(input struct {
+	router Router `paths:"/files/{path:*}" methods:"GET" name:"file.get"`
+	Path   string `path:"path"`
+}) {
+}
+
+func TestAPIURL(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&urlTestRouter{}, "/v1", false)
+	if _, err := api.URL("user.get", "id", "7"); err == nil {
+		t.Fatal("URL should fail before Build")
+	}
+	handler, err := api.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		params  []string
+		want    string
+		wantErr bool
+	}{
+		{name: "user.get", params: []string{"id", "7"}, want: "/v1/users/7"},
+		{name: "file.get", params: []string{"path", "docs/a?.txt"}, want: "/v1/files/docs/a%3F.txt"},
+		{name: "user.get", params: []string{"id", "abc"}, wantErr: true},
+		{name: "user.get", params: []string{"id"}, wantErr: true},
+		{name: "user.get", wantErr: true},
+		{name: "user.get", params: []string{"id", "7", "other", "1"}, wantErr: true},
+		{name: "none", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := api.URL(tt.name, tt.params...)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("URL(%v, %v): got %q %v want %q", tt.name, tt.params, got, err, tt.want)
+		}
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/users/7", nil))
+	if location := w.Header().Get("Location"); location != "/v1/files/a%20b/c.txt" {
+		t.Fatalf("URLFor: got %q", location)
+	}
+}
+
+func TestAPIURLDuplicateName(t *testing.T) {
+	api := New(false)
+	api.SetLogger(nil)
+	api.IncludeRouter(&urlTestRouter{}, "/v1", false)
+	api.IncludeRouter(&urlTestRouter{}, "/v2", false)
+	_, err := api.Build()
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) || len(buildErr.Errors) != 2 {
+		t.Fatalf("Build error: got %v", err)
+	}
+}
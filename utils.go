@@ -4,6 +4,7 @@ import (
 	"encoding"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"path"
 	"reflect"
@@ -82,6 +83,41 @@ func pathJoin(val string, args ...string) string {
 	return val + slash
 }
 
+// cleanPath returns the canonical path, the '.' and '..' elements and the duplicate slashes are removed,
+// the trailing slash is kept
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	np := path.Clean(p)
+	if p[len(p)-1] == '/' && np != "/" {
+		np += "/"
+	}
+	return np
+}
+
+// splitPaths splits the paths by ',', the ',' within the wildcards such as {code:[0-9]{1,3}} is not split
+func splitPaths(pathStr string) (paths []string) {
+	depth, start := 0, 0
+	for i := 0; i < len(pathStr); i++ {
+		switch pathStr[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth <= 0 {
+				paths = append(paths, pathStr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(paths, pathStr[start:])
+}
+
 func removeMorePtr(fType reflect.Type) reflect.Type {
 	for fType.Kind() == reflect.Ptr && fType.Elem().Kind() == reflect.Ptr {
 		fType = fType.Elem()
@@ -451,6 +487,27 @@ func defaultSet(dst reflect.Value, _default any) (isSet bool) {
 	return
 }
 
+// parseForwardedFor returns the 'for' parameters of the RFC 7239 Forwarded header in order
+// example: for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711"
+func parseForwardedFor(values []string) (ips []string) {
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+				val = strings.Trim(val, `"`)
+				if host, _, err := net.SplitHostPort(val); err == nil {
+					val = host
+				}
+				ips = append(ips, strings.TrimSuffix(strings.TrimPrefix(val, "["), "]"))
+			}
+		}
+	}
+	return
+}
+
 func getHTTPError(err error, defaultCode int) error {
 	switch val := err.(type) {
 	case *HTTPError: